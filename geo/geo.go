@@ -33,7 +33,8 @@ var minLonRad = minLon * degreesToRadian
 var minLatRad = minLat * degreesToRadian
 var maxLonRad = maxLon * degreesToRadian
 var maxLatRad = maxLat * degreesToRadian
-var geoTolerance = 1E-6
+// GeoTolerance is the tolerance of the comparisons of lon/lat values
+var GeoTolerance = 1E-6
 var lonScale = float64((uint64(0x1)<<GeoBits)-1) / 360.0
 var latScale = float64((uint64(0x1)<<GeoBits)-1) / 180.0
 
@@ -75,7 +76,7 @@ func unscaleLat(lat uint64) float64 {
 // taking into consideration a known geo tolerance.
 func compareGeo(a, b float64) float64 {
 	compare := a - b
-	if math.Abs(compare) <= geoTolerance {
+	if math.Abs(compare) <= GeoTolerance {
 		return 0
 	}
 	return compare
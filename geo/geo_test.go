@@ -181,3 +181,23 @@ func TestBoundingBoxContains(t *testing.T) {
 		}
 	}
 }
+
+func TestGeoHashEncode(t *testing.T) {
+	tests := []struct {
+		lat, lng float64
+		chars    int
+		expected string
+	}{
+		{lat: 57.64911, lng: 10.40744, chars: 11, expected: "u4pruydqqvj"},
+		{lat: 57.64911, lng: 10.40744, chars: 3, expected: "u4p"},
+		{lat: 37.7749, lng: -122.4194, chars: 6, expected: "9q8yyk"},
+		{lat: -90, lng: -180, chars: 1, expected: "0"},
+		{lat: 90, lng: 180, chars: 1, expected: "z"},
+	}
+	for _, test := range tests {
+		actual := GeoHashEncode(test.lat, test.lng, test.chars)
+		if actual != test.expected {
+			t.Errorf("%f,%f: expected %s, got %s", test.lat, test.lng, test.expected, actual)
+		}
+	}
+}
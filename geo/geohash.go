@@ -121,7 +121,8 @@ func maxDecimalPower(r float64) float64 {
 // Encode the position of x within the range -r to +r as a 32-bit integer.
 func encodeRange(x, r float64) uint32 {
 	p := (x + r) / (2 * r)
-	return uint32(p * exp232)
+	// +r is part of the last range
+	return uint32(math.Min(p*exp232, math.MaxUint32))
 }
 
 // Decode the 32-bit range encoding X back to a value in the range -r to +r.
@@ -172,3 +173,30 @@ func GeoHashDecode(hash string) (lat, lng float64) {
 	box := geoBoundingBox(hash)
 	return box.round()
 }
+
+// Spread out the 32 bits of x into 64 bits, where the bits of x occupy even
+// bit positions.
+func spread(x uint32) uint64 {
+	X := uint64(x)
+	X = (X | (X << 16)) & 0x0000ffff0000ffff
+	X = (X | (X << 8)) & 0x00ff00ff00ff00ff
+	X = (X | (X << 4)) & 0x0f0f0f0f0f0f0f0f
+	X = (X | (X << 2)) & 0x3333333333333333
+	X = (X | (X << 1)) & 0x5555555555555555
+	return X
+}
+
+// Interleave the bits of x and y. In the result, x and y occupy even and odd
+// bitlevels, respectively.
+func interleave(x, y uint32) uint64 {
+	return spread(x) | (spread(y) << 1)
+}
+
+// Encode the (lat, lng) point to a string geohash of the given number of
+// characters, at most 12.
+func GeoHashEncode(lat, lng float64, chars int) string {
+	bits := uint(5 * chars)
+	inthash := interleave(encodeRange(lat, 90), encodeRange(lng, 180))
+	enc := base32encoding.encode(inthash >> (64 - bits))
+	return enc[12-chars:]
+}
@@ -114,9 +114,12 @@ func (b *Batch) String() string {
 }
 
 // Reset returns a Batch to the empty state so that it can
-// be re-used in the future.
+// be re-used in the future, without allocating new maps.
+// The batch must not be reset while it is executing.
 func (b *Batch) Reset() {
 	b.internal.Reset()
+	b.lastDocSize = 0
+	b.totalSize = 0
 }
 
 func (b *Batch) Merge(o *Batch) {
@@ -226,6 +229,15 @@ type Index interface {
 	FieldDict(field string) (index.FieldDict, error)
 	FieldDictRange(field string, startTerm []byte, endTerm []byte) (index.FieldDict, error)
 	FieldDictPrefix(field string, termPrefix []byte) (index.FieldDict, error)
+	// FieldDictRegexp returns the terms of the field entirely matched by
+	// the regular expression.
+	FieldDictRegexp(field string, regex string) (index.FieldDict, error)
+	// FieldDictFuzzy returns the terms of the field starting with the
+	// prefix and within the fuzziness edit distance of the term.
+	FieldDictFuzzy(field string, term string, fuzziness int, prefix string) (index.FieldDict, error)
+	// FieldDictBounded returns the terms of the field within the bounds,
+	// which allow paging through them.
+	FieldDictBounded(field string, bounds index.FieldDictBounds) (index.FieldDict, error)
 
 	Close() error
 
@@ -16,6 +16,7 @@ package index
 
 import (
 	"reflect"
+	"sync"
 
 	"github.com/blevesearch/bleve/analysis"
 	"github.com/blevesearch/bleve/document"
@@ -58,6 +59,42 @@ func (a *AnalysisResult) Size() int {
 	return rv
 }
 
+var analysisResultPool = sync.Pool{
+	New: func() interface{} {
+		return &AnalysisResult{}
+	},
+}
+
+// NewAnalysisResult returns an AnalysisResult for the document, taken
+// from a pool, with Analyzed and Length sized for numFields fields.
+func NewAnalysisResult(d *document.Document, numFields int) *AnalysisResult {
+	rv := analysisResultPool.Get().(*AnalysisResult)
+	rv.Document = d
+	if cap(rv.Analyzed) >= numFields {
+		rv.Analyzed = rv.Analyzed[:numFields]
+		rv.Length = rv.Length[:numFields]
+	} else {
+		rv.Analyzed = make([]analysis.TokenFrequencies, numFields)
+		rv.Length = make([]int, numFields)
+	}
+	return rv
+}
+
+// Recycle returns the AnalysisResult to the pool, after which neither
+// it nor its Analyzed and Length slices may be used.
+func (a *AnalysisResult) Recycle() {
+	for i := range a.Analyzed {
+		a.Analyzed[i] = nil
+		a.Length[i] = 0
+	}
+	a.DocID = ""
+	a.Rows = nil
+	a.Document = nil
+	a.Analyzed = a.Analyzed[:0]
+	a.Length = a.Length[:0]
+	analysisResultPool.Put(a)
+}
+
 type AnalysisWork struct {
 	i  Index
 	d  *document.Document
@@ -121,6 +121,90 @@ type IndexReaderOnly interface {
 	FieldDictOnly(field string, onlyTerms [][]byte, includeCount bool) (FieldDict, error)
 }
 
+// IndexReaderSegmentSort is an optional interface implemented by
+// IndexReaders whose documents are ordered by the value of a field
+// within each of their segments.
+type IndexReaderSegmentSort interface {
+	// SegmentSort returns the field the documents are ordered by, and
+	// whether the order is descending, or an empty field when the
+	// documents are not ordered.
+	SegmentSort() (field string, desc bool)
+
+	// NextSegmentStart returns the ID of the first document of the
+	// segment following the one containing the ID, or nil if the ID
+	// belongs to the last segment.
+	NextSegmentStart(ID IndexInternalID) (IndexInternalID, error)
+}
+
+// IndexReaderPoints is an optional interface implemented by
+// IndexReaders which index the numeric and geo point values of fields
+// in block KD-trees, so that range and bounding box queries don't
+// need to enumerate the prefix coded terms of the fields.  The
+// returned readers provide no freq, norm or term vectors.
+type IndexReaderPoints interface {
+	// NumericRangeReader returns a reader of the documents with a
+	// numeric value of the field in [min, max], as converted by
+	// numeric.Float64ToInt64.
+	NumericRangeReader(field string, min, max int64) (TermFieldReader, error)
+
+	// GeoBoundingBoxReader returns a reader of the documents with a
+	// geo point of the field within the bounding box.
+	GeoBoundingBoxReader(field string, minLon, minLat, maxLon,
+		maxLat float64) (TermFieldReader, error)
+}
+
+// IndexReaderFieldDictBounded is an optional interface implemented by
+// IndexReaders which can enumerate a page of the terms of a field
+// without visiting the terms outside of its bounds.
+type IndexReaderFieldDictBounded interface {
+	FieldDictBounded(field string, bounds FieldDictBounds) (FieldDict, error)
+}
+
+// Automaton is a finite automaton over the bytes of terms, with the
+// same methods as the automata of the vellum package, so that those can
+// be used as is.
+type Automaton interface {
+	// Start returns the start state
+	Start() int
+
+	// IsMatch returns true if and only if the state is a match state
+	IsMatch(int) bool
+
+	// CanMatch returns true if and only if some match state is reachable
+	// from the state
+	CanMatch(int) bool
+
+	// WillAlwaysMatch returns true if and only if the state matches and
+	// will always match no matter what steps are taken
+	WillAlwaysMatch(int) bool
+
+	// Accept returns the next state given the input to the state
+	Accept(int, byte) int
+}
+
+// IndexReaderAutomaton is an optional interface implemented by
+// IndexReaders which can intersect the term dictionary of a field with
+// an automaton, returning the postings of the accepted terms without
+// looking each of them up again.
+type IndexReaderAutomaton interface {
+	// AutomatonTerms returns the terms of the field accepted by the
+	// automaton, within the inclusive start and exclusive end keys,
+	// either of which may be nil when unbounded.
+	AutomatonTerms(field string, a Automaton,
+		startKeyInclusive, endKeyExclusive []byte,
+		includeFreq, includeNorm, includeTermVectors bool) (AutomatonTermIterator, error)
+}
+
+// AutomatonTermIterator iterates in order over the terms accepted by an
+// automaton.
+type AutomatonTermIterator interface {
+	// Next returns the next accepted term and a reader of its postings,
+	// which the caller must close, or a nil reader after the last term.
+	Next() (term []byte, postings TermFieldReader, err error)
+
+	Close() error
+}
+
 // FieldTerms contains the terms used by a document, keyed by field
 type FieldTerms map[string][]string
 
@@ -220,6 +304,19 @@ type TermFieldReader interface {
 	Size() int
 }
 
+// TermFieldReaderImpacts is an optional interface implemented by
+// TermFieldReaders able to report upper bounds on the impact,
+// sqrt(freq) * norm, of the postings they enumerate.
+type TermFieldReaderImpacts interface {
+	// MaxImpact returns an upper bound on the impact of any posting.
+	MaxImpact() float64
+
+	// BlockMaxImpact returns an upper bound on the impact of the
+	// postings in the block containing the ID, along with the ID where
+	// the following block starts, or nil if there is no following block.
+	BlockMaxImpact(ID IndexInternalID) (float64, IndexInternalID, error)
+}
+
 type DictEntry struct {
 	Term  string
 	Count uint64
@@ -230,6 +327,94 @@ type FieldDict interface {
 	Close() error
 }
 
+// FieldDictBounds restricts the terms enumerated by a bounded field
+// dictionary, allowing callers to page through the terms of a field by
+// passing the last term of a page as the exclusive start of the next.
+type FieldDictBounds struct {
+	// Prefix, when not nil, restricts the terms to those starting with it
+	Prefix []byte
+
+	// Start and End, when not nil, bound the terms, which include the
+	// bounds themselves unless StartExclusive or EndExclusive is set
+	Start          []byte
+	StartExclusive bool
+	End            []byte
+	EndExclusive   bool
+
+	// Limit, when greater than 0, is the maximum number of terms
+	Limit int
+}
+
+// KeyRange returns the range of the terms within the bounds, as an
+// inclusive start and an exclusive end, either of which is nil when
+// unbounded.
+func (b *FieldDictBounds) KeyRange() (start, end []byte) {
+	if b.Start != nil {
+		start = append([]byte(nil), b.Start...)
+		if b.StartExclusive {
+			start = append(start, 0)
+		}
+	}
+	if b.End != nil {
+		end = append([]byte(nil), b.End...)
+		if !b.EndExclusive {
+			end = append(end, 0)
+		}
+	}
+	if b.Prefix != nil {
+		if start == nil || bytes.Compare(b.Prefix, start) > 0 {
+			start = append([]byte(nil), b.Prefix...)
+		}
+		prefixEnd := incrementBytes(b.Prefix)
+		if prefixEnd != nil && (end == nil || bytes.Compare(prefixEnd, end) < 0) {
+			end = prefixEnd
+		}
+	}
+	return start, end
+}
+
+// Empty returns true when no term can be within the bounds
+func (b *FieldDictBounds) Empty() bool {
+	start, end := b.KeyRange()
+	return end != nil && bytes.Compare(start, end) >= 0
+}
+
+// incrementBytes returns the smallest key greater than all the keys
+// prefixed by in, or nil if there is none
+func incrementBytes(in []byte) []byte {
+	rv := append([]byte(nil), in...)
+	for i := len(rv) - 1; i >= 0; i-- {
+		rv[i]++
+		if rv[i] != 0 {
+			return rv[:i+1]
+		}
+	}
+	return nil
+}
+
+// NewLimitedFieldDict returns a FieldDict ending the iteration of the
+// FieldDict after limit terms, or the FieldDict itself if the limit is
+// not greater than 0.
+func NewLimitedFieldDict(d FieldDict, limit int) FieldDict {
+	if limit <= 0 {
+		return d
+	}
+	return &limitedFieldDict{FieldDict: d, remaining: limit}
+}
+
+type limitedFieldDict struct {
+	FieldDict
+	remaining int
+}
+
+func (d *limitedFieldDict) Next() (*DictEntry, error) {
+	if d.remaining <= 0 {
+		return nil, nil
+	}
+	d.remaining--
+	return d.FieldDict.Next()
+}
+
 // DocIDReader is the interface exposing enumeration of documents identifiers.
 // Close the reader to release associated resources.
 type DocIDReader interface {
@@ -306,9 +491,15 @@ func (b *Batch) String() string {
 	return rv
 }
 
+// Reset empties the batch, keeping its maps so that reusing it avoids
+// their allocation.
 func (b *Batch) Reset() {
-	b.IndexOps = make(map[string]*document.Document)
-	b.InternalOps = make(map[string][]byte)
+	for k := range b.IndexOps {
+		delete(b.IndexOps, k)
+	}
+	for k := range b.InternalOps {
+		delete(b.InternalOps, k)
+	}
 	b.persistedCallback = nil
 }
 
@@ -359,3 +550,34 @@ type OptimizableContext interface {
 type DocValueReader interface {
 	VisitDocValues(id IndexInternalID, visitor DocumentFieldTermVisitor) error
 }
+
+// DocValueSummary summarizes the terms of a field of a document, taken
+// in increasing order, as needed to sort documents by that field.
+type DocValueSummary struct {
+	First []byte
+	Last  []byte
+
+	// FirstShiftZero and LastShiftZero are the first and last terms
+	// which are prefix coded numbers with a shift of 0, if any.
+	FirstShiftZero []byte
+	LastShiftZero  []byte
+
+	// AllPrefixCoded is true when all the terms are prefix coded numbers.
+	AllPrefixCoded bool
+}
+
+type DocValueSummaryVisitor func(field string, summary *DocValueSummary)
+
+// IndexReaderDocValueSummary is an optional interface implemented by
+// IndexReaders able to summarize the terms of the fields of a document
+// without visiting each of them, for example from memoized per-segment
+// term ordinals.
+type IndexReaderDocValueSummary interface {
+	DocValueSummaryReader(fields []string) (DocValueSummaryReader, error)
+}
+
+type DocValueSummaryReader interface {
+	// VisitDocValueSummaries invokes the visitor for each of the fields
+	// of the document having terms.
+	VisitDocValueSummaries(id IndexInternalID, visitor DocValueSummaryVisitor) error
+}
@@ -29,6 +29,18 @@ type Event struct {
 	Kind     EventKind
 	Scorch   *Scorch
 	Duration time.Duration
+
+	// Epoch is the epoch of the root snapshot swapped in by the
+	// segment introduced, persisted or merged events.
+	Epoch uint64
+
+	// Segment is the ID of the segment introduced by the segment
+	// introduced and merged events.
+	Segment uint64
+
+	// Segments are the IDs of the segments persisted, merged into
+	// Segment or purged, for the corresponding events.
+	Segments []uint64
 }
 
 // EventKind represents an event code for OnEvent() callbacks.
@@ -54,3 +66,20 @@ var EventKindBatchIntroductionStart = EventKind(5)
 
 // EventKindBatchIntroduction is fired when Batch() completes.
 var EventKindBatchIntroduction = EventKind(6)
+
+// EventKindSegmentIntroduced is fired when the segment of a batch has
+// been introduced into a new root snapshot by the introducer.
+var EventKindSegmentIntroduced = EventKind(7)
+
+// EventKindSegmentsPersisted is fired when in-memory segments have been
+// replaced in a new root snapshot by the segments the persister wrote
+// to disk.
+var EventKindSegmentsPersisted = EventKind(8)
+
+// EventKindSegmentsMerged is fired when segments have been replaced in
+// a new root snapshot by the segment the merger merged them into.
+var EventKindSegmentsMerged = EventKind(9)
+
+// EventKindSegmentsPurged is fired when the files of segments no longer
+// referenced by any persisted snapshot have been removed.
+var EventKindSegmentsPurged = EventKind(10)
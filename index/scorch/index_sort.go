@@ -0,0 +1,223 @@
+//  Copyright (c) 2019 Couchbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 		http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorch
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/index/scorch/segment"
+	"github.com/blevesearch/bleve/index/scorch/segment/zap"
+	"github.com/blevesearch/bleve/numeric"
+)
+
+// indexSort describes the order of the documents within each segment,
+// by the value of a field, as a search.SortField with the default
+// type, mode and missing options would see it, so that searches
+// sorting the same way can stop early on each segment
+type indexSort struct {
+	field string
+	desc  bool
+}
+
+// parseIndexSort parses the "indexSort" config value, which is a field
+// name, prefixed with "-" for a descending order
+func parseIndexSort(v interface{}) (*indexSort, error) {
+	if v == nil {
+		return nil, nil
+	}
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("indexSort expects a string value")
+	}
+	rv := &indexSort{field: s}
+	if strings.HasPrefix(s, "-") {
+		rv.field = s[1:]
+		rv.desc = true
+	}
+	if rv.field == "" {
+		if rv.desc {
+			return nil, fmt.Errorf("indexSort missing field name")
+		}
+		return nil, nil
+	}
+	if rv.field == "_id" || rv.field == "_score" {
+		return nil, fmt.Errorf("indexSort unsupported for field: %s", rv.field)
+	}
+	return rv, nil
+}
+
+// String returns the indexSort in its config form, and the empty
+// string for a nil indexSort
+func (is *indexSort) String() string {
+	if is == nil {
+		return ""
+	}
+	if is.desc {
+		return "-" + is.field
+	}
+	return is.field
+}
+
+// compare orders two sort keys, with missing (nil) keys last
+func (is *indexSort) compare(a, b []byte) int {
+	if a == nil || b == nil {
+		if a == nil && b == nil {
+			return 0
+		}
+		if a == nil {
+			return 1
+		}
+		return -1
+	}
+	if is.desc {
+		return bytes.Compare(b, a)
+	}
+	return bytes.Compare(a, b)
+}
+
+// sortKey computes the sort key of a document from the terms of the
+// field, visited in increasing order, like search.SortField does
+type sortKey struct {
+	first          []byte
+	firstShiftZero []byte
+	allPrefixCoded bool
+	any            bool
+}
+
+func (k *sortKey) reset() {
+	*k = sortKey{allPrefixCoded: true}
+}
+
+func (k *sortKey) visit(term []byte) {
+	if !k.any {
+		k.first = term
+		k.any = true
+	}
+	valid, shift := numeric.ValidPrefixCodedTermBytes(term)
+	if valid && shift == 0 {
+		if k.firstShiftZero == nil {
+			k.firstShiftZero = term
+		}
+	} else if !valid {
+		k.allPrefixCoded = false
+	}
+}
+
+// value returns the sort key, or nil if the document has none
+func (k *sortKey) value() []byte {
+	if !k.any {
+		return nil
+	}
+	if k.allPrefixCoded {
+		return k.firstShiftZero
+	}
+	return k.first
+}
+
+// sortAnalysisResults orders the analyzed documents of a batch, before
+// they're converted into a segment
+func (is *indexSort) sortAnalysisResults(results []*index.AnalysisResult) {
+	keys := make(map[*index.AnalysisResult][]byte, len(results))
+	var k sortKey
+	var terms []string
+	for _, result := range results {
+		terms = terms[:0]
+		for i, field := range result.Document.Fields {
+			if field.Name() == is.field && i < len(result.Analyzed) {
+				for term := range result.Analyzed[i] {
+					terms = append(terms, term)
+				}
+			}
+		}
+		sort.Strings(terms)
+
+		k.reset()
+		for _, term := range terms {
+			k.visit([]byte(term))
+		}
+		keys[result] = k.value()
+	}
+
+	sort.SliceStable(results, func(i, j int) bool {
+		return is.compare(keys[results[i]], keys[results[j]]) < 0
+	})
+}
+
+// segmentKeys returns the sort keys of the documents of a segment,
+// indexed by docNum
+func (is *indexSort) segmentKeys(seg segment.Segment) ([][]byte, error) {
+	keys := make([]sortKey, seg.Count())
+	for i := range keys {
+		keys[i].reset()
+	}
+
+	dict, err := seg.Dictionary(is.field)
+	if err != nil {
+		return nil, err
+	}
+
+	var postings segment.PostingsList
+	var postingsItr segment.PostingsIterator
+
+	dictItr := dict.Iterator()
+	next, err := dictItr.Next()
+	for next != nil && err == nil {
+		term := []byte(next.Term)
+		postings, err = dict.PostingsList(term, nil, postings)
+		if err != nil {
+			return nil, err
+		}
+		postingsItr = postings.Iterator(false, false, false, postingsItr)
+		posting, err := postingsItr.Next()
+		for posting != nil && err == nil {
+			keys[posting.Number()].visit(term)
+			posting, err = postingsItr.Next()
+		}
+		if err != nil {
+			return nil, err
+		}
+		next, err = dictItr.Next()
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	rv := make([][]byte, len(keys))
+	for i := range keys {
+		rv[i] = keys[i].value()
+	}
+	return rv, nil
+}
+
+// docLess returns the order of the documents of the segments being
+// merged, which keeps the merged segment sorted
+func (is *indexSort) docLess(segments []segment.Segment) (zap.DocLess, error) {
+	keys := make([][][]byte, len(segments))
+	for i, seg := range segments {
+		var err error
+		keys[i], err = is.segmentKeys(seg)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return func(segI int, docNumI uint64, segJ int, docNumJ uint64) bool {
+		return is.compare(keys[segI][docNumI], keys[segJ][docNumJ]) < 0
+	}, nil
+}
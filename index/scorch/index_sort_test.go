@@ -0,0 +1,216 @@
+//  Copyright (c) 2019 Couchbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 		http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorch
+
+import (
+	"fmt"
+	"os"
+	"testing"
+
+	"github.com/RoaringBitmap/roaring"
+	"github.com/blevesearch/bleve/document"
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/index/scorch/segment"
+	"github.com/blevesearch/bleve/index/scorch/segment/zap"
+)
+
+func TestParseIndexSort(t *testing.T) {
+	tests := []struct {
+		in       interface{}
+		expected *indexSort
+		err      bool
+	}{
+		{in: nil},
+		{in: ""},
+		{in: "n", expected: &indexSort{field: "n"}},
+		{in: "-n", expected: &indexSort{field: "n", desc: true}},
+		{in: "-", err: true},
+		{in: "_id", err: true},
+		{in: "-_score", err: true},
+		{in: 1.0, err: true},
+	}
+
+	for _, test := range tests {
+		actual, err := parseIndexSort(test.in)
+		if (err != nil) != test.err {
+			t.Errorf("%v: expected err %t, got %v", test.in, test.err, err)
+		}
+		if actual.String() != test.expected.String() {
+			t.Errorf("%v: expected %q, got %q", test.in, test.expected, actual)
+		}
+	}
+}
+
+func indexSortTestDoc(i int) *document.Document {
+	doc := document.NewDocument(fmt.Sprintf("%03d", i))
+	doc.AddField(document.NewTextFieldWithAnalyzer("desc", []uint64{},
+		[]byte(fmt.Sprintf("doc %d", i%7)), testAnalyzer))
+	if i%5 != 0 {
+		// some documents miss the sort field
+		doc.AddField(document.NewNumericField("n", []uint64{}, float64((i*37)%101)))
+	}
+	doc.AddField(document.NewTextFieldCustom("_id", nil, []byte(doc.ID),
+		document.IndexField|document.StoreField, nil))
+	return doc
+}
+
+func checkSegmentSorted(t *testing.T, is *indexSort, seg segment.Segment) {
+	keys, err := is.segmentKeys(seg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 1; i < len(keys); i++ {
+		if is.compare(keys[i-1], keys[i]) > 0 {
+			t.Errorf("doc %d, key %x sorted before %x", i-1, keys[i-1], keys[i])
+		}
+	}
+}
+
+func TestIndexSortSegments(t *testing.T) {
+	is := &indexSort{field: "n", desc: true}
+	s := &Scorch{}
+
+	var sbs []*zap.SegmentBase
+	var segs []segment.Segment
+	for b := 0; b < 3; b++ {
+		var results []*index.AnalysisResult
+		for i := 0; i < 20; i++ {
+			results = append(results, s.Analyze(indexSortTestDoc(b*20+i)))
+		}
+		is.sortAnalysisResults(results)
+
+		sb, _, err := zap.AnalysisResultsToSegmentBase(results, 4)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkSegmentSorted(t, is, sb)
+
+		sbs = append(sbs, sb)
+		segs = append(segs, sb)
+	}
+
+	less, err := is.docLess(segs)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	path := os.TempDir() + "/bleve-scorch-test-index-sort.zap"
+	_ = os.RemoveAll(path)
+	defer func() {
+		_ = os.RemoveAll(path)
+	}()
+
+	drops := []*roaring.Bitmap{nil, roaring.BitmapOf(3, 4, 5), nil}
+	_, _, err = zap.MergeSegmentBasesSorted(sbs, drops, path, 4, less, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	merged, err := zap.Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		_ = merged.Close()
+	}()
+
+	if merged.Count() != 57 {
+		t.Errorf("expected 57 docs, got %d", merged.Count())
+	}
+	checkSegmentSorted(t, is, merged)
+}
+
+func TestIndexSortOpen(t *testing.T) {
+	cfg := CreateConfig("TestIndexSortOpen")
+	err := InitTest(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := DestroyTest(cfg)
+		if err != nil {
+			t.Log(err)
+		}
+	}()
+	cfg["indexSort"] = "n"
+
+	analysisQueue := index.NewAnalysisQueue(1)
+	idx, err := NewScorch(Name, cfg, analysisQueue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = idx.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for b := 0; b < 5; b++ {
+		batch := index.NewBatch()
+		for i := 0; i < 20; i++ {
+			doc := indexSortTestDoc(b*20 + i)
+			batch.Update(doc)
+		}
+		err = idx.Batch(batch)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reader, err := idx.Reader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	field, desc := reader.(index.IndexReaderSegmentSort).SegmentSort()
+	if field != "n" || desc {
+		t.Errorf("expected segment sort n, got %s, desc: %t", field, desc)
+	}
+	for _, segSnapshot := range reader.(*IndexSnapshot).segment {
+		checkSegmentSorted(t, idx.(*Scorch).indexSort, segSnapshot.segment)
+	}
+	err = reader.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = idx.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// reopening with another sort must fail
+	cfg["indexSort"] = "-n"
+	idx, err = NewScorch(Name, cfg, analysisQueue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = idx.Open()
+	if err == nil {
+		_ = idx.Close()
+		t.Fatalf("expected error opening index with another indexSort")
+	}
+
+	cfg["indexSort"] = "n"
+	idx, err = NewScorch(Name, cfg, analysisQueue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = idx.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = idx.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -52,7 +52,11 @@ type snapshotReversion struct {
 	persisted chan error
 }
 
-func (s *Scorch) mainLoop() {
+// introducerLoop is the only goroutine swapping in new root snapshots,
+// introducing the segments of batches along with those written by the
+// persister and the merger, so that none of them needs to lock the
+// others out.
+func (s *Scorch) introducerLoop() {
 	var epochWatchers []*epochWatcher
 OUTER:
 	for {
@@ -233,6 +237,14 @@ func (s *Scorch) introduceSegment(next *segmentIntroduction) error {
 
 	close(next.applied)
 
+	if next.data != nil {
+		s.fireEventDetails(Event{
+			Kind:    EventKindSegmentIntroduced,
+			Epoch:   newSnapshot.epoch,
+			Segment: next.id,
+		})
+	}
+
 	return nil
 }
 
@@ -259,10 +271,12 @@ func (s *Scorch) introducePersist(persist *persistIntroduction) {
 		creator:  "introducePersist",
 	}
 
+	var persistedIDs []uint64
 	var docsToPersistCount, memSegments, fileSegments uint64
 	for i, segmentSnapshot := range root.segment {
 		// see if this segment has been replaced
 		if replacement, ok := persist.persisted[segmentSnapshot.id]; ok {
+			persistedIDs = append(persistedIDs, segmentSnapshot.id)
 			newSegmentSnapshot := &SegmentSnapshot{
 				id:         segmentSnapshot.id,
 				segment:    replacement,
@@ -310,6 +324,14 @@ func (s *Scorch) introducePersist(persist *persistIntroduction) {
 	}
 
 	close(persist.applied)
+
+	if len(persistedIDs) > 0 {
+		s.fireEventDetails(Event{
+			Kind:     EventKindSegmentsPersisted,
+			Epoch:    nextSnapshotEpoch,
+			Segments: persistedIDs,
+		})
+	}
 }
 
 func (s *Scorch) introduceMerge(nextMerge *segmentMerge) {
@@ -332,10 +354,12 @@ func (s *Scorch) introduceMerge(nextMerge *segmentMerge) {
 
 	// iterate through current segments
 	newSegmentDeleted := roaring.NewBitmap()
+	mergedIDs := make([]uint64, 0, len(nextMerge.old))
 	var running, docsToPersistCount, memSegments, fileSegments uint64
 	for i := range root.segment {
 		segmentID := root.segment[i].id
 		if segSnapAtMerge, ok := nextMerge.old[segmentID]; ok {
+			mergedIDs = append(mergedIDs, segmentID)
 			// this segment is going away, see if anything else was deleted since we started the merge
 			if segSnapAtMerge != nil && root.segment[i].deleted != nil {
 				// assume all these deletes are new
@@ -383,6 +407,7 @@ func (s *Scorch) introduceMerge(nextMerge *segmentMerge) {
 	// merged segment wrt the current root segments, hence
 	// applying the obsolete segment contents to newly merged segment
 	for segID, ss := range nextMerge.old {
+		mergedIDs = append(mergedIDs, segID)
 		obsoleted := ss.DocNumbersLive()
 		if obsoleted != nil {
 			obsoletedIter := obsoleted.Iterator()
@@ -441,6 +466,13 @@ func (s *Scorch) introduceMerge(nextMerge *segmentMerge) {
 	// notify requester that we incorporated this
 	nextMerge.notify <- newSnapshot
 	close(nextMerge.notify)
+
+	s.fireEventDetails(Event{
+		Kind:     EventKindSegmentsMerged,
+		Epoch:    newSnapshot.epoch,
+		Segment:  nextMerge.id,
+		Segments: mergedIDs,
+	})
 }
 
 func (s *Scorch) revertToSnapshot(revertTo *snapshotReversion) error {
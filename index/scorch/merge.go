@@ -196,8 +196,23 @@ func (s *Scorch) planMergeAtSnapshot(ourSnapshot *IndexSnapshot,
 			fileMergeZapStartTime := time.Now()
 
 			atomic.AddUint64(&s.stats.TotFileMergeZapBeg, 1)
-			newDocNums, _, err := zap.Merge(segmentsToMerge, docsToDrop, path,
-				DefaultChunkFactor, s.closeCh, s)
+			var newDocNums [][]uint64
+			var err error
+			if s.indexSort != nil {
+				segs := make([]segment.Segment, len(segmentsToMerge))
+				for i, zapSeg := range segmentsToMerge {
+					segs[i] = zapSeg
+				}
+				var less zap.DocLess
+				less, err = s.indexSort.docLess(segs)
+				if err == nil {
+					newDocNums, _, err = zap.MergeSorted(segmentsToMerge, docsToDrop,
+						path, DefaultChunkFactor, less, s.closeCh, s)
+				}
+			} else {
+				newDocNums, _, err = zap.Merge(segmentsToMerge, docsToDrop, path,
+					DefaultChunkFactor, s.closeCh, s)
+			}
 			atomic.AddUint64(&s.stats.TotFileMergeZapEnd, 1)
 
 			fileMergeZapTime := uint64(time.Since(fileMergeZapStartTime))
@@ -290,8 +305,23 @@ func (s *Scorch) mergeSegmentBases(snapshot *IndexSnapshot,
 	filename := zapFileName(newSegmentID)
 	path := s.path + string(os.PathSeparator) + filename
 
-	newDocNums, _, err :=
-		zap.MergeSegmentBases(sbs, sbsDrops, path, chunkFactor, s.closeCh, s)
+	var newDocNums [][]uint64
+	var err error
+	if s.indexSort != nil {
+		segs := make([]segment.Segment, len(sbs))
+		for i, sb := range sbs {
+			segs[i] = sb
+		}
+		var less zap.DocLess
+		less, err = s.indexSort.docLess(segs)
+		if err == nil {
+			newDocNums, _, err = zap.MergeSegmentBasesSorted(sbs, sbsDrops,
+				path, chunkFactor, less, s.closeCh, s)
+		}
+	} else {
+		newDocNums, _, err =
+			zap.MergeSegmentBases(sbs, sbsDrops, path, chunkFactor, s.closeCh, s)
+	}
 
 	atomic.AddUint64(&s.stats.TotMemMergeZapEnd, 1)
 
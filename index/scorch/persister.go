@@ -442,6 +442,12 @@ func (s *Scorch) persistSnapshotDirect(snapshot *IndexSnapshot) (err error) {
 	if err != nil {
 		return err
 	}
+	if s.indexSort != nil {
+		err = metaBucket.Put(boltMetaIndexSortKey, []byte(s.indexSort.String()))
+		if err != nil {
+			return err
+		}
+	}
 
 	// persist internal values
 	internalBucket, err := snapshotBucket.CreateBucketIfNotExists(boltInternalKey)
@@ -582,6 +588,7 @@ var boltPathKey = []byte{'p'}
 var boltDeletedKey = []byte{'d'}
 var boltInternalKey = []byte{'i'}
 var boltMetaDataKey = []byte{'m'}
+var boltMetaIndexSortKey = []byte("indexSort")
 
 func (s *Scorch) loadFromBolt() error {
 	return s.rootBolt.View(func(tx *bolt.Tx) error {
@@ -607,6 +614,10 @@ func (s *Scorch) loadFromBolt() error {
 				s.AddEligibleForRemoval(snapshotEpoch)
 				continue
 			}
+			err = s.checkIndexSort(snapshot)
+			if err != nil {
+				return err
+			}
 			indexSnapshot, err := s.loadSnapshot(snapshot)
 			if err != nil {
 				log.Printf("unable to load snapshot, %v, continuing", err)
@@ -658,6 +669,21 @@ func (s *Scorch) LoadSnapshot(epoch uint64) (rv *IndexSnapshot, err error) {
 	return rv, nil
 }
 
+// checkIndexSort verifies that the segments of the snapshot were
+// sorted the way the index is configured to sort them
+func (s *Scorch) checkIndexSort(snapshot *bolt.Bucket) error {
+	var persisted string
+	metaBucket := snapshot.Bucket(boltMetaDataKey)
+	if metaBucket != nil {
+		persisted = string(metaBucket.Get(boltMetaIndexSortKey))
+	}
+	if persisted != s.indexSort.String() {
+		return fmt.Errorf("indexSort mismatch, index was sorted by: %q,"+
+			" configured with: %q", persisted, s.indexSort.String())
+	}
+	return nil
+}
+
 func (s *Scorch) loadSnapshot(snapshot *bolt.Bucket) (*IndexSnapshot, error) {
 
 	rv := &IndexSnapshot{
@@ -869,6 +895,7 @@ func (s *Scorch) removeOldZapFiles() error {
 
 	s.rootLock.RLock()
 
+	var purgedIDs []uint64
 	for _, finfo := range currFileInfos {
 		fname := finfo.Name()
 		if filepath.Ext(fname) == ".zap" {
@@ -876,6 +903,9 @@ func (s *Scorch) removeOldZapFiles() error {
 				err := os.Remove(s.path + string(os.PathSeparator) + fname)
 				if err != nil {
 					log.Printf("got err removing file: %s, err: %v", fname, err)
+				} else if id, err := strconv.ParseUint(
+					strings.TrimSuffix(fname, ".zap"), 16, 64); err == nil {
+					purgedIDs = append(purgedIDs, id)
 				}
 			}
 		}
@@ -883,6 +913,13 @@ func (s *Scorch) removeOldZapFiles() error {
 
 	s.rootLock.RUnlock()
 
+	if len(purgedIDs) > 0 {
+		s.fireEventDetails(Event{
+			Kind:     EventKindSegmentsPurged,
+			Segments: purgedIDs,
+		})
+	}
+
 	return nil
 }
 
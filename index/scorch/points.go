@@ -0,0 +1,352 @@
+//  Copyright (c) 2019 Couchbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 		http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorch
+
+import (
+	"reflect"
+	"sort"
+	"sync/atomic"
+
+	"github.com/RoaringBitmap/roaring"
+	"github.com/blevesearch/bleve/geo"
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/index/scorch/segment"
+	"github.com/blevesearch/bleve/index/scorch/segment/zap"
+	"github.com/blevesearch/bleve/numeric"
+	"github.com/blevesearch/bleve/size"
+)
+
+var reflectStaticSizeBKDNode int
+
+func init() {
+	var n bkdNode
+	reflectStaticSizeBKDNode = int(reflect.TypeOf(n).Size())
+}
+
+// PointsLeafSize is a compile time setting that applications can
+// adjust to control the maximum number of points in the leaf blocks of
+// the block KD-trees, which are scanned point by point when they cross
+// the boundary of a query.
+var PointsLeafSize = 512
+
+// bkdTree is a block KD-tree of the points of a field, of 1 (numeric)
+// or 2 (geo lon, lat) dimensions, with the values of each dimension
+// converted to sortable int64's.  The points are reordered so that the
+// points of each node are contiguous.
+type bkdTree struct {
+	dims    int
+	points  []int64  // dims values per point
+	docNums []uint32 // one per point
+	nodes   []bkdNode
+}
+
+type bkdNode struct {
+	start, end  int      // range of the points of the node
+	min, max    [2]int64 // bounds of the points of the node
+	left, right int32    // child nodes, or -1 for leaf blocks
+}
+
+func newBKDTree(dims int, points []int64, docNums []uint32) *bkdTree {
+	rv := &bkdTree{
+		dims:    dims,
+		points:  points,
+		docNums: docNums,
+	}
+	if len(docNums) > 0 {
+		rv.build(0, len(docNums))
+	}
+	return rv
+}
+
+func (t *bkdTree) build(start, end int) int32 {
+	n := int32(len(t.nodes))
+	node := bkdNode{start: start, end: end, left: -1, right: -1}
+	for d := 0; d < t.dims; d++ {
+		node.min[d] = t.points[start*t.dims+d]
+		node.max[d] = node.min[d]
+	}
+	for i := start + 1; i < end; i++ {
+		for d := 0; d < t.dims; d++ {
+			v := t.points[i*t.dims+d]
+			if v < node.min[d] {
+				node.min[d] = v
+			}
+			if v > node.max[d] {
+				node.max[d] = v
+			}
+		}
+	}
+	t.nodes = append(t.nodes, node)
+
+	if end-start <= PointsLeafSize {
+		return n
+	}
+
+	// split the points at the median of the widest dimension
+	dim := 0
+	if t.dims > 1 && uint64(node.max[1]-node.min[1]) > uint64(node.max[0]-node.min[0]) {
+		dim = 1
+	}
+	sort.Sort(&bkdSorter{t: t, start: start, end: end, dim: dim})
+
+	mid := start + (end-start)/2
+	left := t.build(start, mid)
+	right := t.build(mid, end)
+	t.nodes[n].left, t.nodes[n].right = left, right
+
+	return n
+}
+
+type bkdSorter struct {
+	t          *bkdTree
+	start, end int
+	dim        int
+}
+
+func (s *bkdSorter) Len() int { return s.end - s.start }
+
+func (s *bkdSorter) Less(i, j int) bool {
+	dims := s.t.dims
+	return s.t.points[(s.start+i)*dims+s.dim] < s.t.points[(s.start+j)*dims+s.dim]
+}
+
+func (s *bkdSorter) Swap(i, j int) {
+	t := s.t
+	i, j = s.start+i, s.start+j
+	for d := 0; d < t.dims; d++ {
+		t.points[i*t.dims+d], t.points[j*t.dims+d] = t.points[j*t.dims+d], t.points[i*t.dims+d]
+	}
+	t.docNums[i], t.docNums[j] = t.docNums[j], t.docNums[i]
+}
+
+// query adds the docNums of the points within [min, max] to the bitmap
+func (t *bkdTree) query(min, max [2]int64, bm *roaring.Bitmap) {
+	if len(t.nodes) > 0 {
+		t.visit(0, min, max, bm)
+	}
+}
+
+func (t *bkdTree) visit(n int32, min, max [2]int64, bm *roaring.Bitmap) {
+	node := &t.nodes[n]
+	within := true
+	for d := 0; d < t.dims; d++ {
+		if node.max[d] < min[d] || node.min[d] > max[d] {
+			return
+		}
+		if node.min[d] < min[d] || node.max[d] > max[d] {
+			within = false
+		}
+	}
+
+	if within {
+		bm.AddMany(t.docNums[node.start:node.end])
+		return
+	}
+
+	if node.left < 0 {
+	POINTS:
+		for i := node.start; i < node.end; i++ {
+			for d := 0; d < t.dims; d++ {
+				v := t.points[i*t.dims+d]
+				if v < min[d] || v > max[d] {
+					continue POINTS
+				}
+			}
+			bm.Add(t.docNums[i])
+		}
+		return
+	}
+
+	t.visit(node.left, min, max, bm)
+	t.visit(node.right, min, max, bm)
+}
+
+func (t *bkdTree) size() uint64 {
+	return uint64(len(t.points)*size.SizeOfUint64 + len(t.docNums)*size.SizeOfUint32 +
+		len(t.nodes)*reflectStaticSizeBKDNode)
+}
+
+// cachedFieldPoints memoizes the block KD-tree of a field of a segment
+type cachedFieldPoints struct {
+	readyCh chan struct{} // closed when the cachedFieldPoints.tree is ready to be used.
+	err     error         // Non-nil if there was an error when preparing this cachedFieldPoints.
+	tree    *bkdTree
+}
+
+func (cfp *cachedFieldPoints) prepareField(field string, geoPoints bool,
+	ss *SegmentSnapshot) {
+	defer close(cfp.readyCh)
+
+	dict, err := ss.segment.Dictionary(field)
+	if err != nil {
+		cfp.err = err
+		return
+	}
+
+	dims := 1
+	if geoPoints {
+		dims = 2
+	}
+	var points []int64
+	var docNums []uint32
+
+	var postings segment.PostingsList
+	var postingsItr segment.PostingsIterator
+
+	// the full precision terms, with a shift of 0, are the points
+	dictItr := dict.PrefixIterator(string([]byte{numeric.ShiftStartInt64}))
+	next, err := dictItr.Next()
+	for err == nil && next != nil {
+		term := []byte(next.Term)
+		valid, shift := numeric.ValidPrefixCodedTermBytes(term)
+		if !valid || shift != 0 {
+			next, err = dictItr.Next()
+			continue
+		}
+
+		i64, err1 := numeric.PrefixCoded(term).Int64()
+		if err1 != nil {
+			cfp.err = err1
+			return
+		}
+		var point [2]int64
+		point[0] = i64
+		if geoPoints {
+			point[0] = numeric.Float64ToInt64(geo.MortonUnhashLon(uint64(i64)))
+			point[1] = numeric.Float64ToInt64(geo.MortonUnhashLat(uint64(i64)))
+		}
+
+		postings, err1 = dict.PostingsList(term, nil, postings)
+		if err1 != nil {
+			cfp.err = err1
+			return
+		}
+
+		postingsItr = postings.Iterator(false, false, false, postingsItr)
+		nextPosting, err2 := postingsItr.Next()
+		for err2 == nil && nextPosting != nil {
+			points = append(points, point[:dims]...)
+			docNums = append(docNums, uint32(nextPosting.Number()))
+			nextPosting, err2 = postingsItr.Next()
+		}
+
+		if err2 != nil {
+			cfp.err = err2
+			return
+		}
+
+		next, err = dictItr.Next()
+	}
+
+	if err != nil {
+		cfp.err = err
+		return
+	}
+
+	cfp.tree = newBKDTree(dims, points, docNums)
+}
+
+// preparePoints returns the block KD-tree of the field, once it's
+// ready to be used
+func (c *cachedDocs) preparePoints(field string, geoPoints bool,
+	ss *SegmentSnapshot) (*bkdTree, error) {
+	key := "n:" + field
+	if geoPoints {
+		key = "g:" + field
+	}
+
+	c.m.Lock()
+
+	if c.points == nil {
+		c.points = make(map[string]*cachedFieldPoints)
+	}
+
+	cfp, exists := c.points[key]
+	if !exists {
+		cfp = &cachedFieldPoints{
+			readyCh: make(chan struct{}),
+		}
+		c.points[key] = cfp
+
+		go cfp.prepareField(field, geoPoints, ss)
+	}
+
+	c.m.Unlock()
+
+	<-cfp.readyCh
+
+	if cfp.err != nil {
+		return nil, cfp.err
+	}
+
+	if !exists {
+		c.m.Lock()
+		c.updateSizeLOCKED()
+		c.m.Unlock()
+	}
+
+	return cfp.tree, nil
+}
+
+func (i *IndexSnapshot) NumericRangeReader(field string,
+	min, max int64) (index.TermFieldReader, error) {
+	return i.pointsReader(field, false, [2]int64{min}, [2]int64{max})
+}
+
+func (i *IndexSnapshot) GeoBoundingBoxReader(field string,
+	minLon, minLat, maxLon, maxLat float64) (index.TermFieldReader, error) {
+	return i.pointsReader(field, true,
+		[2]int64{
+			numeric.Float64ToInt64(minLon - geo.GeoTolerance),
+			numeric.Float64ToInt64(minLat - geo.GeoTolerance),
+		},
+		[2]int64{
+			numeric.Float64ToInt64(maxLon + geo.GeoTolerance),
+			numeric.Float64ToInt64(maxLat + geo.GeoTolerance),
+		})
+}
+
+// pointsReader returns a reader over the bitmaps of the documents with
+// points of the field within [min, max] in each segment
+func (i *IndexSnapshot) pointsReader(field string, geoPoints bool,
+	min, max [2]int64) (index.TermFieldReader, error) {
+	rv := &IndexSnapshotTermFieldReader{
+		field:     field,
+		snapshot:  i,
+		iterators: make([]segment.PostingsIterator, len(i.segment)),
+	}
+
+	for segmentIndex, ss := range i.segment {
+		tree, err := ss.cachedDocs.preparePoints(field, geoPoints, ss)
+		if err != nil {
+			return nil, err
+		}
+
+		bm := roaring.New()
+		tree.query(min, max, bm)
+		if ss.deleted != nil {
+			bm.AndNot(ss.deleted)
+		}
+		rv.count += bm.GetCardinality()
+
+		rv.iterators[segmentIndex], err = zap.PostingsIteratorFromBitmap(bm, false, false)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	atomic.AddUint64(&i.parent.stats.TotTermSearchersStarted, uint64(1))
+	return rv, nil
+}
@@ -0,0 +1,74 @@
+//  Copyright (c) 2019 Couchbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 		http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorch
+
+import (
+	"math/rand"
+	"testing"
+
+	"github.com/RoaringBitmap/roaring"
+)
+
+func TestBKDTreeQuery(t *testing.T) {
+	defaultLeafSize := PointsLeafSize
+	PointsLeafSize = 8
+	defer func() {
+		PointsLeafSize = defaultLeafSize
+	}()
+
+	r := rand.New(rand.NewSource(42))
+	for _, dims := range []int{1, 2} {
+		for _, n := range []int{0, 1, 7, 100, 1000} {
+			var points []int64
+			var docNums []uint32
+			for i := 0; i < n; i++ {
+				for d := 0; d < dims; d++ {
+					points = append(points, r.Int63n(200)-100)
+				}
+				docNums = append(docNums, uint32(i/2)) // some docs have 2 points
+			}
+			expectedPoints := append([]int64(nil), points...)
+
+			tree := newBKDTree(dims, points, docNums)
+
+			for q := 0; q < 50; q++ {
+				var min, max [2]int64
+				for d := 0; d < dims; d++ {
+					min[d] = r.Int63n(240) - 120
+					max[d] = min[d] + r.Int63n(120)
+				}
+
+				expected := roaring.New()
+			POINTS:
+				for i := 0; i < n; i++ {
+					for d := 0; d < dims; d++ {
+						v := expectedPoints[i*dims+d]
+						if v < min[d] || v > max[d] {
+							continue POINTS
+						}
+					}
+					expected.Add(uint32(i / 2))
+				}
+
+				actual := roaring.New()
+				tree.query(min, max, actual)
+				if !actual.Equals(expected) {
+					t.Errorf("dims: %d, n: %d, [%v, %v], expected %v, got %v",
+						dims, n, min, max, expected.ToArray(), actual.ToArray())
+				}
+			}
+		}
+	}
+}
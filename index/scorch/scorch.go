@@ -24,7 +24,6 @@ import (
 	"time"
 
 	"github.com/RoaringBitmap/roaring"
-	"github.com/blevesearch/bleve/analysis"
 	"github.com/blevesearch/bleve/document"
 	"github.com/blevesearch/bleve/index"
 	"github.com/blevesearch/bleve/index/scorch/segment"
@@ -51,6 +50,8 @@ type Scorch struct {
 
 	unsafeBatch bool
 
+	indexSort *indexSort
+
 	rootLock             sync.RWMutex
 	root                 *IndexSnapshot // holds 1 ref-count on the root
 	rootPersisted        []chan error   // closed when root is persisted
@@ -119,6 +120,11 @@ func NewScorch(storeName string,
 	if ok {
 		rv.onAsyncError = RegistryAsyncErrorCallbacks[aecbName]
 	}
+	is, err := parseIndexSort(config["indexSort"])
+	if err != nil {
+		return nil, err
+	}
+	rv.indexSort = is
 	return rv, nil
 }
 
@@ -142,9 +148,16 @@ func (s *Scorch) decrPause() {
 }
 
 func (s *Scorch) fireEvent(kind EventKind, dur time.Duration) {
+	s.fireEventDetails(Event{Kind: kind, Duration: dur})
+}
+
+// fireEventDetails fires an event carrying more than its kind and
+// duration, such as the segment lifecycle events
+func (s *Scorch) fireEventDetails(e Event) {
 	if s.onEvent != nil {
+		e.Scorch = s
 		s.incrPause()
-		s.onEvent(Event{Kind: kind, Scorch: s, Duration: dur})
+		s.onEvent(e)
 		s.decrPause()
 	}
 }
@@ -163,7 +176,7 @@ func (s *Scorch) Open() error {
 	}
 
 	s.asyncTasks.Add(1)
-	go s.mainLoop()
+	go s.introducerLoop()
 
 	if !s.readOnly && s.path != "" {
 		s.asyncTasks.Add(1)
@@ -338,6 +351,12 @@ func (s *Scorch) Batch(batch *index.Batch) (err error) {
 	}
 	close(resultChan)
 	defer atomic.AddUint64(&s.iStats.analysisBytesRemoved, uint64(totalAnalysisSize))
+	defer func() {
+		// the new segment holds a copy of everything it needs
+		for _, result := range analysisResults {
+			result.Recycle()
+		}
+	}()
 
 	atomic.AddUint64(&s.stats.TotAnalysisTime, uint64(time.Since(start)))
 
@@ -349,6 +368,9 @@ func (s *Scorch) Batch(batch *index.Batch) (err error) {
 	var newSegment segment.Segment
 	var bufBytes uint64
 	if len(analysisResults) > 0 {
+		if s.indexSort != nil {
+			s.indexSort.sortAnalysisResults(analysisResults)
+		}
 		newSegment, bufBytes, err = zap.AnalysisResultsToSegmentBase(analysisResults, DefaultChunkFactor)
 		if err != nil {
 			return err
@@ -516,11 +538,7 @@ func (s *Scorch) StatsMap() map[string]interface{} {
 }
 
 func (s *Scorch) Analyze(d *document.Document) *index.AnalysisResult {
-	rv := &index.AnalysisResult{
-		Document: d,
-		Analyzed: make([]analysis.TokenFrequencies, len(d.Fields)+len(d.CompositeFields)),
-		Length:   make([]int, len(d.Fields)+len(d.CompositeFields)),
-	}
+	rv := index.NewAnalysisResult(d, len(d.Fields)+len(d.CompositeFields))
 
 	for i, field := range d.Fields {
 		if field.Options().IsIndexed() {
@@ -33,6 +33,7 @@ import (
 	"github.com/blevesearch/bleve/document"
 	"github.com/blevesearch/bleve/index"
 	"github.com/blevesearch/bleve/mapping"
+	"github.com/blevesearch/bleve/search"
 )
 
 func init() {
@@ -2011,3 +2012,150 @@ func TestAllFieldWithDifferentTermVectorsEnabled(t *testing.T) {
 		t.Errorf("Error updating index: %v", err)
 	}
 }
+
+func TestIndexDocValueSummaries(t *testing.T) {
+	cfg := CreateConfig("TestIndexDocValueSummaries")
+	err := InitTest(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := DestroyTest(cfg)
+		if err != nil {
+			t.Log(err)
+		}
+	}()
+
+	analysisQueue := index.NewAnalysisQueue(1)
+	idx, err := NewScorch(Name, cfg, analysisQueue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = idx.Open()
+	if err != nil {
+		t.Fatalf("error opening index: %v", err)
+	}
+	defer func() {
+		err := idx.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// several batches, so several segments
+	for b := 0; b < 3; b++ {
+		batch := index.NewBatch()
+		for i := 0; i < 30; i++ {
+			n := b*30 + i
+			doc := document.NewDocument(strconv.Itoa(n))
+			for j := 0; j < n%4; j++ {
+				doc.AddField(document.NewTextFieldWithAnalyzer("tags", []uint64{uint64(j)},
+					[]byte(fmt.Sprintf("tag%d", (n*7+j*13)%17)), testAnalyzer))
+				doc.AddField(document.NewNumericField("n", []uint64{uint64(j)},
+					float64((n*37+j*11)%101)))
+			}
+			if n%3 == 0 {
+				doc.AddField(document.NewTextFieldWithAnalyzer("n", []uint64{},
+					[]byte("text"), testAnalyzer))
+			}
+			if n%5 != 0 {
+				doc.AddField(document.NewGeoPointField("loc", []uint64{},
+					float64(n%90), float64(n%45)))
+			}
+			batch.Update(doc)
+		}
+		if b > 0 {
+			batch.Delete(strconv.Itoa(b))
+		}
+		err = idx.Batch(batch)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reader, err := idx.Reader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := reader.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	geoSort, err := search.NewSortGeoDistance("loc", "km", 10, 20, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sorts := search.SortOrder{geoSort}
+	for _, field := range []string{"tags", "n"} {
+		for _, typ := range []search.SortFieldType{search.SortFieldAuto,
+			search.SortFieldAsString, search.SortFieldAsNumber} {
+			for _, mode := range []search.SortFieldMode{search.SortFieldDefault,
+				search.SortFieldMin, search.SortFieldMax} {
+				sorts = append(sorts, &search.SortField{
+					Field: field,
+					Type:  typ,
+					Mode:  mode,
+				})
+			}
+		}
+	}
+	if !sorts.Summarizable() {
+		t.Fatalf("expected sorts to be summarizable")
+	}
+	fields := sorts.RequiredFields()
+
+	dvReader, err := reader.DocValueReader(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+	dvSummaryReader, err := reader.(index.IndexReaderDocValueSummary).
+		DocValueSummaryReader(fields)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	docIDReader, err := reader.DocIDReaderAll()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := docIDReader.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	var docs int
+	id, err := docIDReader.Next()
+	for err == nil && id != nil {
+		expected := &search.DocumentMatch{}
+		err = dvReader.VisitDocValues(id, sorts.UpdateVisitor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sorts.Value(expected)
+
+		actual := &search.DocumentMatch{}
+		err = dvSummaryReader.VisitDocValueSummaries(id, sorts.UpdateSummary)
+		if err != nil {
+			t.Fatal(err)
+		}
+		sorts.Value(actual)
+
+		if !reflect.DeepEqual(actual.Sort, expected.Sort) {
+			t.Errorf("doc %x, expected sort values %q, got %q",
+				id, expected.Sort, actual.Sort)
+		}
+		docs++
+		id, err = docIDReader.Next()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	if docs != 88 {
+		t.Errorf("expected 88 docs, got %d", docs)
+	}
+}
@@ -65,6 +65,13 @@ type DictionaryIterator interface {
 	Next() (*index.DictEntry, error)
 }
 
+// DictionaryIteratorPostings is an optional interface implemented by
+// DictionaryIterators able to return the postings list of the term last
+// returned by Next, without looking the term up again.
+type DictionaryIteratorPostings interface {
+	PostingsList(except *roaring.Bitmap, prealloc PostingsList) (PostingsList, error)
+}
+
 type PostingsList interface {
 	Iterator(includeFreq, includeNorm, includeLocations bool, prealloc PostingsIterator) PostingsIterator
 
@@ -94,6 +101,19 @@ type PostingsIterator interface {
 	Size() int
 }
 
+// PostingsIteratorImpacts is an optional interface implemented by
+// postings iterators able to report upper bounds on the impact,
+// sqrt(freq) * norm, of their postings.
+type PostingsIteratorImpacts interface {
+	// MaxImpact returns an upper bound on the impact of all postings.
+	MaxImpact() float64
+
+	// BlockMaxImpact returns an upper bound on the impact of the
+	// postings in the block containing the docNum, along with the
+	// docNum where the following block starts.
+	BlockMaxImpact(docNum uint64) (impact float64, nextBlockStart uint64)
+}
+
 type Posting interface {
 	Number() uint64
 
@@ -236,6 +236,8 @@ type DictionaryIterator struct {
 	tmp       PostingsList
 	entry     index.DictEntry
 	omitCount bool
+
+	postingsOffset uint64
 }
 
 // Next returns the next entry in the dictionary
@@ -247,6 +249,7 @@ func (i *DictionaryIterator) Next() (*index.DictEntry, error) {
 	}
 	term, postingsOffset := i.itr.Current()
 	i.entry.Term = string(term)
+	i.postingsOffset = postingsOffset
 	if !i.omitCount {
 		i.err = i.tmp.read(postingsOffset, i.d)
 		if i.err != nil {
@@ -257,3 +260,11 @@ func (i *DictionaryIterator) Next() (*index.DictEntry, error) {
 	i.err = i.itr.Next()
 	return &i.entry, nil
 }
+
+// PostingsList returns the postings list of the term last returned by
+// Next
+func (i *DictionaryIterator) PostingsList(except *roaring.Bitmap,
+	prealloc segment.PostingsList) (segment.PostingsList, error) {
+	preallocPL, _ := prealloc.(*PostingsList)
+	return i.d.postingsListFromOffset(i.postingsOffset, except, preallocPL)
+}
@@ -0,0 +1,108 @@
+//  Copyright (c) 2019 Couchbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 		http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zap
+
+import (
+	"encoding/binary"
+	"io"
+	"math"
+)
+
+// chunkedImpacts tracks, for each chunk of a postings list, the maximum
+// impact of the postings in that chunk, where the impact of a posting
+// is sqrt(freq) * norm.  Scorers multiply the impact by term level
+// factors, so these maximums bound the scores of a chunk's postings
+// without decoding them.
+type chunkedImpacts struct {
+	chunkSize uint64
+	maxes     []float32
+
+	buf []byte
+}
+
+func newChunkedImpacts(chunkSize uint64, maxDocNum uint64) *chunkedImpacts {
+	total := maxDocNum/chunkSize + 1
+	return &chunkedImpacts{
+		chunkSize: chunkSize,
+		maxes:     make([]float32, total),
+	}
+}
+
+// Reset lets you reuse the chunked impacts for another postings list.
+func (c *chunkedImpacts) Reset() {
+	for i := range c.maxes {
+		c.maxes[i] = 0
+	}
+}
+
+// Add records the impact of the posting for the provided doc num.
+func (c *chunkedImpacts) Add(docNum uint64, freq uint64, normBits uint64) {
+	impact := postingImpact(freq, math.Float32frombits(uint32(normBits)))
+	chunk := docNum / c.chunkSize
+	if impact > c.maxes[chunk] {
+		c.maxes[chunk] = impact
+	}
+}
+
+// Write commits the number of chunks followed by the maximum impact of
+// each chunk to the provided writer.
+func (c *chunkedImpacts) Write(w io.Writer) (int, error) {
+	bufNeeded := binary.MaxVarintLen64 * (1 + len(c.maxes))
+	if len(c.buf) < bufNeeded {
+		c.buf = make([]byte, bufNeeded)
+	}
+
+	n := binary.PutUvarint(c.buf, uint64(len(c.maxes)))
+	for _, max := range c.maxes {
+		n += binary.PutUvarint(c.buf[n:], uint64(math.Float32bits(max)))
+	}
+
+	return w.Write(c.buf[:n])
+}
+
+// postingImpact computes sqrt(freq) * norm, rounded up to the next
+// float32 so that the stored value is never below the float64 product
+// computed at scoring time.
+func postingImpact(freq uint64, norm float32) float32 {
+	exact := math.Sqrt(float64(freq)) * float64(norm)
+	rv := float32(exact)
+	if float64(rv) < exact {
+		rv = math.Nextafter32(rv, float32(math.Inf(1)))
+	}
+	return rv
+}
+
+// readImpacts decodes the per chunk maximum impacts at the provided
+// offset into the (optionally preallocated) slice.
+func (sb *SegmentBase) readImpacts(offset uint64, rv []float32) []float32 {
+	var n uint64
+	numChunks, read := binary.Uvarint(sb.mem[offset : offset+binary.MaxVarintLen64])
+	n += uint64(read)
+
+	if cap(rv) >= int(numChunks) {
+		rv = rv[:int(numChunks)]
+	} else {
+		rv = make([]float32, int(numChunks))
+	}
+
+	for i := 0; i < int(numChunks); i++ {
+		var bits uint64
+		bits, read = binary.Uvarint(sb.mem[offset+n : offset+n+binary.MaxVarintLen64])
+		n += uint64(read)
+		rv[i] = math.Float32frombits(uint32(bits))
+	}
+
+	return rv
+}
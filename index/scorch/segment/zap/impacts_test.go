@@ -0,0 +1,149 @@
+//  Copyright (c) 2019 Couchbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 		http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zap
+
+import (
+	"math"
+	"os"
+	"testing"
+
+	"github.com/RoaringBitmap/roaring"
+)
+
+func TestImpacts(t *testing.T) {
+	for _, chunkFactor := range []uint32{1, 2, 1024} {
+		seg, _, err := buildTestSegmentMultiWithChunkFactor(chunkFactor)
+		if err != nil {
+			t.Fatal(err)
+		}
+		checkImpacts(t, seg)
+	}
+}
+
+func TestMergeImpacts(t *testing.T) {
+	_ = os.RemoveAll("/tmp/scorch.zap")
+	_ = os.RemoveAll("/tmp/scorch2.zap")
+	_ = os.RemoveAll("/tmp/scorch3.zap")
+
+	testSeg, _, _ := buildTestSegmentMultiWithChunkFactor(1)
+	err := PersistSegmentBase(testSeg, "/tmp/scorch.zap")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	testSeg2, _, _ := buildTestSegmentMulti2()
+	err = PersistSegmentBase(testSeg2, "/tmp/scorch2.zap")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	segment, err := Open("/tmp/scorch.zap")
+	if err != nil {
+		t.Fatalf("error opening segment: %v", err)
+	}
+	defer func() {
+		cerr := segment.Close()
+		if cerr != nil {
+			t.Fatalf("error closing segment: %v", err)
+		}
+	}()
+
+	segment2, err := Open("/tmp/scorch2.zap")
+	if err != nil {
+		t.Fatalf("error opening segment: %v", err)
+	}
+	defer func() {
+		cerr := segment2.Close()
+		if cerr != nil {
+			t.Fatalf("error closing segment: %v", err)
+		}
+	}()
+
+	segsToMerge := []*Segment{segment.(*Segment), segment2.(*Segment)}
+
+	_, _, err = Merge(segsToMerge, []*roaring.Bitmap{nil, nil}, "/tmp/scorch3.zap", 1, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	segm, err := Open("/tmp/scorch3.zap")
+	if err != nil {
+		t.Fatalf("error opening merged segment: %v", err)
+	}
+	seg3 := segm.(*Segment)
+	defer func() {
+		cerr := seg3.Close()
+		if cerr != nil {
+			t.Fatalf("error closing segment: %v", err)
+		}
+	}()
+
+	checkImpacts(t, &seg3.SegmentBase)
+}
+
+// checkImpacts verifies that the stored impacts bound the impact of
+// every posting in the segment
+func checkImpacts(t *testing.T, sb *SegmentBase) {
+	for _, field := range sb.Fields() {
+		dict, err := sb.Dictionary(field)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		dictItr := dict.Iterator()
+		next, err := dictItr.Next()
+		for next != nil && err == nil {
+			pl, err := dict.PostingsList([]byte(next.Term), nil, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			itr := pl.Iterator(true, false, false, nil).(*PostingsIterator)
+
+			maxImpact := itr.MaxImpact()
+			if math.IsInf(maxImpact, 1) {
+				t.Fatalf("expected impacts for field: %s, term: %s", field, next.Term)
+			}
+
+			posting, err := itr.Next()
+			for posting != nil && err == nil {
+				impact := math.Sqrt(float64(posting.Frequency())) * posting.Norm()
+				if impact > maxImpact {
+					t.Errorf("field: %s, term: %s, doc: %d, impact: %f above max: %f",
+						field, next.Term, posting.Number(), impact, maxImpact)
+				}
+
+				blockMax, nextBlock := itr.BlockMaxImpact(posting.Number())
+				if impact > blockMax {
+					t.Errorf("field: %s, term: %s, doc: %d, impact: %f above block max: %f",
+						field, next.Term, posting.Number(), impact, blockMax)
+				}
+				if nextBlock <= posting.Number() {
+					t.Errorf("field: %s, term: %s, doc: %d, next block: %d not after doc",
+						field, next.Term, posting.Number(), nextBlock)
+				}
+
+				posting, err = itr.Next()
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			next, err = dictItr.Next()
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -45,12 +45,42 @@ func Merge(segments []*Segment, drops []*roaring.Bitmap, path string,
 		segmentBases[segmenti] = &segment.SegmentBase
 	}
 
-	return MergeSegmentBases(segmentBases, drops, path, chunkFactor, closeCh, s)
+	return mergeSegmentBases(segmentBases, drops, path, chunkFactor, nil, closeCh, s)
+}
+
+// MergeSorted is like Merge, except that the documents of the new
+// segment are ordered according to the provided less function, with
+// documents that compare equal keeping their relative order.
+func MergeSorted(segments []*Segment, drops []*roaring.Bitmap, path string,
+	chunkFactor uint32, less DocLess, closeCh chan struct{}, s seg.StatsReporter) (
+	[][]uint64, uint64, error) {
+	segmentBases := make([]*SegmentBase, len(segments))
+	for segmenti, segment := range segments {
+		segmentBases[segmenti] = &segment.SegmentBase
+	}
+
+	return mergeSegmentBases(segmentBases, drops, path, chunkFactor, less, closeCh, s)
 }
 
 func MergeSegmentBases(segmentBases []*SegmentBase, drops []*roaring.Bitmap, path string,
 	chunkFactor uint32, closeCh chan struct{}, s seg.StatsReporter) (
 	[][]uint64, uint64, error) {
+	return mergeSegmentBases(segmentBases, drops, path, chunkFactor, nil, closeCh, s)
+}
+
+// MergeSegmentBasesSorted is like MergeSegmentBases, except that the
+// documents of the new segment are ordered according to the provided
+// less function, with documents that compare equal keeping their
+// relative order.
+func MergeSegmentBasesSorted(segmentBases []*SegmentBase, drops []*roaring.Bitmap,
+	path string, chunkFactor uint32, less DocLess, closeCh chan struct{},
+	s seg.StatsReporter) ([][]uint64, uint64, error) {
+	return mergeSegmentBases(segmentBases, drops, path, chunkFactor, less, closeCh, s)
+}
+
+func mergeSegmentBases(segmentBases []*SegmentBase, drops []*roaring.Bitmap, path string,
+	chunkFactor uint32, less DocLess, closeCh chan struct{}, s seg.StatsReporter) (
+	[][]uint64, uint64, error) {
 	flag := os.O_RDWR | os.O_CREATE
 
 	f, err := os.OpenFile(path, flag, 0600)
@@ -70,7 +100,7 @@ func MergeSegmentBases(segmentBases []*SegmentBase, drops []*roaring.Bitmap, pat
 	cr := NewCountHashWriterWithStatsReporter(br, s)
 
 	newDocNums, numDocs, storedIndexOffset, fieldsIndexOffset, docValueOffset, _, _, _, err :=
-		MergeToWriter(segmentBases, drops, chunkFactor, cr, closeCh)
+		mergeToWriter(segmentBases, drops, chunkFactor, less, cr, closeCh)
 	if err != nil {
 		cleanup()
 		return nil, 0, err
@@ -110,6 +140,15 @@ func MergeToWriter(segments []*SegmentBase, drops []*roaring.Bitmap,
 	numDocs, storedIndexOffset, fieldsIndexOffset, docValueOffset uint64,
 	dictLocs []uint64, fieldsInv []string, fieldsMap map[string]uint16,
 	err error) {
+	return mergeToWriter(segments, drops, chunkFactor, nil, cr, closeCh)
+}
+
+func mergeToWriter(segments []*SegmentBase, drops []*roaring.Bitmap,
+	chunkFactor uint32, less DocLess, cr *CountHashWriter, closeCh chan struct{}) (
+	newDocNums [][]uint64,
+	numDocs, storedIndexOffset, fieldsIndexOffset, docValueOffset uint64,
+	dictLocs []uint64, fieldsInv []string, fieldsMap map[string]uint16,
+	err error) {
 	docValueOffset = uint64(fieldNotUninverted)
 
 	var fieldsSame bool
@@ -123,14 +162,19 @@ func MergeToWriter(segments []*SegmentBase, drops []*roaring.Bitmap,
 	}
 
 	if numDocs > 0 {
+		var order []mergeDoc
+		if less != nil {
+			order = sortMergeDocs(segments, drops, numDocs, less)
+		}
+
 		storedIndexOffset, newDocNums, err = mergeStoredAndRemap(segments, drops,
-			fieldsMap, fieldsInv, fieldsSame, numDocs, cr, closeCh)
+			fieldsMap, fieldsInv, fieldsSame, numDocs, order, cr, closeCh)
 		if err != nil {
 			return nil, 0, 0, 0, 0, nil, nil, nil, err
 		}
 
 		dictLocs, docValueOffset, err = persistMergedRest(segments, drops,
-			fieldsInv, fieldsMap, fieldsSame,
+			fieldsInv, fieldsMap, fieldsSame, order != nil,
 			newDocNums, numDocs, chunkFactor, cr, closeCh)
 		if err != nil {
 			return nil, 0, 0, 0, 0, nil, nil, nil, err
@@ -172,12 +216,22 @@ func computeNewDocCount(segments []*SegmentBase, drops []*roaring.Bitmap) uint64
 
 func persistMergedRest(segments []*SegmentBase, dropsIn []*roaring.Bitmap,
 	fieldsInv []string, fieldsMap map[string]uint16, fieldsSame bool,
-	newDocNumsIn [][]uint64, newSegDocCount uint64, chunkFactor uint32,
-	w *CountHashWriter, closeCh chan struct{}) ([]uint64, uint64, error) {
+	reordered bool, newDocNumsIn [][]uint64, newSegDocCount uint64,
+	chunkFactor uint32, w *CountHashWriter, closeCh chan struct{}) (
+	[]uint64, uint64, error) {
 
 	var bufMaxVarintLen64 []byte = make([]byte, binary.MaxVarintLen64)
 	var bufLoc []uint64
 
+	// when the documents are reordered, the postings and doc values
+	// are buffered, to be encoded in increasing new docNum order
+	var bufPostings *bufferedPostings
+	var bufDocValues *bufferedDocValues
+	if reordered {
+		bufPostings = &bufferedPostings{}
+		bufDocValues = &bufferedDocValues{}
+	}
+
 	var postings *PostingsList
 	var postItr *PostingsIterator
 
@@ -187,6 +241,7 @@ func persistMergedRest(segments []*SegmentBase, dropsIn []*roaring.Bitmap,
 
 	tfEncoder := newChunkedIntCoder(uint64(chunkFactor), newSegDocCount-1)
 	locEncoder := newChunkedIntCoder(uint64(chunkFactor), newSegDocCount-1)
+	impacts := newChunkedImpacts(uint64(chunkFactor), newSegDocCount-1)
 
 	var vellumBuf bytes.Buffer
 	newVellum, err := vellum.New(&vellumBuf, nil)
@@ -257,11 +312,20 @@ func persistMergedRest(segments []*SegmentBase, dropsIn []*roaring.Bitmap,
 		}
 
 		finishTerm := func(term []byte) error {
+			if bufPostings != nil {
+				var err error
+				lastDocNum, lastFreq, lastNorm, err =
+					bufPostings.flush(tfEncoder, locEncoder, impacts)
+				if err != nil {
+					return err
+				}
+			}
+
 			tfEncoder.Close()
 			locEncoder.Close()
 
 			postingsOffset, err := writePostings(newRoaring,
-				tfEncoder, locEncoder, use1HitEncoding, w, bufMaxVarintLen64)
+				tfEncoder, locEncoder, impacts, use1HitEncoding, w, bufMaxVarintLen64)
 			if err != nil {
 				return err
 			}
@@ -277,6 +341,7 @@ func persistMergedRest(segments []*SegmentBase, dropsIn []*roaring.Bitmap,
 
 			tfEncoder.Reset()
 			locEncoder.Reset()
+			impacts.Reset()
 
 			lastDocNum = 0
 			lastFreq = 0
@@ -312,15 +377,18 @@ func persistMergedRest(segments []*SegmentBase, dropsIn []*roaring.Bitmap,
 
 			postItr = postings.iterator(true, true, true, postItr)
 
-			if fieldsSame {
+			if bufPostings != nil {
+				bufLoc, err = bufPostings.add(fieldsMap, postItr,
+					newDocNums[itrI], newRoaring, bufLoc)
+			} else if fieldsSame {
 				// can optimize by copying freq/norm/loc bytes directly
 				lastDocNum, lastFreq, lastNorm, err = mergeTermFreqNormLocsByCopying(
 					term, postItr, newDocNums[itrI], newRoaring,
-					tfEncoder, locEncoder)
+					tfEncoder, locEncoder, impacts)
 			} else {
 				lastDocNum, lastFreq, lastNorm, bufLoc, err = mergeTermFreqNormLocs(
 					fieldsMap, term, postItr, newDocNums[itrI], newRoaring,
-					tfEncoder, locEncoder, bufLoc)
+					tfEncoder, locEncoder, impacts, bufLoc)
 			}
 			if err != nil {
 				return nil, 0, err
@@ -386,6 +454,10 @@ func persistMergedRest(segments []*SegmentBase, dropsIn []*roaring.Bitmap,
 					if newDocNums[segmentI][docNum] == docDropped {
 						return nil
 					}
+					if bufDocValues != nil {
+						bufDocValues.add(newDocNums[segmentI][docNum], terms)
+						return nil
+					}
 					err := fdvEncoder.Add(newDocNums[segmentI][docNum], terms)
 					if err != nil {
 						return err
@@ -399,6 +471,13 @@ func persistMergedRest(segments []*SegmentBase, dropsIn []*roaring.Bitmap,
 		}
 
 		if fdvReadersAvailable {
+			if bufDocValues != nil {
+				err = bufDocValues.flush(fdvEncoder)
+				if err != nil {
+					return nil, 0, err
+				}
+			}
+
 			err = fdvEncoder.Close()
 			if err != nil {
 				return nil, 0, err
@@ -446,7 +525,8 @@ func persistMergedRest(segments []*SegmentBase, dropsIn []*roaring.Bitmap,
 
 func mergeTermFreqNormLocs(fieldsMap map[string]uint16, term []byte, postItr *PostingsIterator,
 	newDocNums []uint64, newRoaring *roaring.Bitmap,
-	tfEncoder *chunkedIntCoder, locEncoder *chunkedIntCoder, bufLoc []uint64) (
+	tfEncoder *chunkedIntCoder, locEncoder *chunkedIntCoder,
+	impacts *chunkedImpacts, bufLoc []uint64) (
 	lastDocNum uint64, lastFreq uint64, lastNorm uint64, bufLocOut []uint64, err error) {
 	next, err := postItr.Next()
 	for next != nil && err == nil {
@@ -468,6 +548,8 @@ func mergeTermFreqNormLocs(fieldsMap map[string]uint16, term []byte, postItr *Po
 			return 0, 0, 0, nil, err
 		}
 
+		impacts.Add(hitNewDocNum, nextFreq, nextNorm)
+
 		if len(locs) > 0 {
 			numBytesLocs := 0
 			for _, loc := range locs {
@@ -512,7 +594,8 @@ func mergeTermFreqNormLocs(fieldsMap map[string]uint16, term []byte, postItr *Po
 
 func mergeTermFreqNormLocsByCopying(term []byte, postItr *PostingsIterator,
 	newDocNums []uint64, newRoaring *roaring.Bitmap,
-	tfEncoder *chunkedIntCoder, locEncoder *chunkedIntCoder) (
+	tfEncoder *chunkedIntCoder, locEncoder *chunkedIntCoder,
+	impacts *chunkedImpacts) (
 	lastDocNum uint64, lastFreq uint64, lastNorm uint64, err error) {
 	nextDocNum, nextFreq, nextNorm, nextFreqNormBytes, nextLocBytes, err :=
 		postItr.nextBytes()
@@ -528,6 +611,8 @@ func mergeTermFreqNormLocsByCopying(term []byte, postItr *PostingsIterator,
 			return 0, 0, 0, err
 		}
 
+		impacts.Add(hitNewDocNum, nextFreq, nextNorm)
+
 		if len(nextLocBytes) > 0 {
 			err = locEncoder.AddBytes(hitNewDocNum, nextLocBytes)
 			if err != nil {
@@ -547,7 +632,7 @@ func mergeTermFreqNormLocsByCopying(term []byte, postItr *PostingsIterator,
 }
 
 func writePostings(postings *roaring.Bitmap, tfEncoder, locEncoder *chunkedIntCoder,
-	use1HitEncoding func(uint64) (bool, uint64, uint64),
+	impacts *chunkedImpacts, use1HitEncoding func(uint64) (bool, uint64, uint64),
 	w *CountHashWriter, bufMaxVarintLen64 []byte) (
 	offset uint64, err error) {
 	termCardinality := postings.GetCardinality()
@@ -574,6 +659,12 @@ func writePostings(postings *roaring.Bitmap, tfEncoder, locEncoder *chunkedIntCo
 		return 0, err
 	}
 
+	impactsOffset := uint64(w.Count())
+	_, err = impacts.Write(w)
+	if err != nil {
+		return 0, err
+	}
+
 	postingsOffset := uint64(w.Count())
 
 	n := binary.PutUvarint(bufMaxVarintLen64, tfOffset)
@@ -588,6 +679,12 @@ func writePostings(postings *roaring.Bitmap, tfEncoder, locEncoder *chunkedIntCo
 		return 0, err
 	}
 
+	n = binary.PutUvarint(bufMaxVarintLen64, impactsOffset)
+	_, err = w.Write(bufMaxVarintLen64[:n])
+	if err != nil {
+		return 0, err
+	}
+
 	_, err = writeRoaringWithLen(postings, w, bufMaxVarintLen64)
 	if err != nil {
 		return 0, err
@@ -600,7 +697,7 @@ type varintEncoder func(uint64) (int, error)
 
 func mergeStoredAndRemap(segments []*SegmentBase, drops []*roaring.Bitmap,
 	fieldsMap map[string]uint16, fieldsInv []string, fieldsSame bool, newSegDocCount uint64,
-	w *CountHashWriter, closeCh chan struct{}) (uint64, [][]uint64, error) {
+	order []mergeDoc, w *CountHashWriter, closeCh chan struct{}) (uint64, [][]uint64, error) {
 	var rv [][]uint64 // The remapped or newDocNums for each segment.
 
 	var newDocNum uint64
@@ -625,6 +722,131 @@ func mergeStoredAndRemap(segments []*SegmentBase, drops []*roaring.Bitmap,
 	vdc := visitDocumentCtxPool.Get().(*visitDocumentCtx)
 	defer visitDocumentCtxPool.Put(vdc)
 
+	// writeDoc writes out the stored fields of the segment's docNum as
+	// the newDocNum of the merged segment
+	writeDoc := func(segment *SegmentBase, docNum uint64, newDocNum uint64) error {
+		curr = 0
+		metaBuf.Reset()
+		data = data[:0]
+
+		posTemp := posBuf
+
+		// collect all the data
+		for i := 0; i < len(fieldsInv); i++ {
+			vals[i] = vals[i][:0]
+			typs[i] = typs[i][:0]
+			poss[i] = poss[i][:0]
+		}
+		err := segment.visitDocument(vdc, docNum, func(field string, typ byte, value []byte, pos []uint64) bool {
+			fieldID := int(fieldsMap[field]) - 1
+			vals[fieldID] = append(vals[fieldID], value)
+			typs[fieldID] = append(typs[fieldID], typ)
+
+			// copy array positions to preserve them beyond the scope of this callback
+			var curPos []uint64
+			if len(pos) > 0 {
+				if cap(posTemp) < len(pos) {
+					posBuf = make([]uint64, len(pos)*len(fieldsInv))
+					posTemp = posBuf
+				}
+				curPos = posTemp[0:len(pos)]
+				copy(curPos, pos)
+				posTemp = posTemp[len(pos):]
+			}
+			poss[fieldID] = append(poss[fieldID], curPos)
+
+			return true
+		})
+		if err != nil {
+			return err
+		}
+
+		// _id field special case optimizes ExternalID() lookups
+		idFieldVal := vals[uint16(0)][0]
+		_, err = metaEncode(uint64(len(idFieldVal)))
+		if err != nil {
+			return err
+		}
+
+		// now walk the non-"_id" fields in order
+		for fieldID := 1; fieldID < len(fieldsInv); fieldID++ {
+			storedFieldValues := vals[fieldID]
+
+			stf := typs[fieldID]
+			spf := poss[fieldID]
+
+			var err2 error
+			curr, data, err2 = persistStoredFieldValues(fieldID,
+				storedFieldValues, stf, spf, curr, metaEncode, data)
+			if err2 != nil {
+				return err2
+			}
+		}
+
+		metaBytes := metaBuf.Bytes()
+
+		compressed = snappy.Encode(compressed[:cap(compressed)], data)
+
+		// record where we're about to start writing
+		docNumOffsets[newDocNum] = uint64(w.Count())
+
+		// write out the meta len and compressed data len
+		_, err = writeUvarints(w,
+			uint64(len(metaBytes)),
+			uint64(len(idFieldVal)+len(compressed)))
+		if err != nil {
+			return err
+		}
+		// now write the meta
+		_, err = w.Write(metaBytes)
+		if err != nil {
+			return err
+		}
+		// now write the _id field val (counted as part of the 'compressed' data)
+		_, err = w.Write(idFieldVal)
+		if err != nil {
+			return err
+		}
+		// now write the compressed data
+		_, err = w.Write(compressed)
+		if err != nil {
+			return err
+		}
+
+		return nil
+	}
+
+	if order != nil {
+		// the documents are reordered, so write them out in their new
+		// order, remapping the dropped ones
+		for _, segment := range segments {
+			segNewDocNums := make([]uint64, segment.numDocs)
+			for docNum := range segNewDocNums {
+				segNewDocNums[docNum] = docDropped
+			}
+			rv = append(rv, segNewDocNums)
+		}
+
+		for _, doc := range order {
+			// check for the closure in meantime
+			if isClosed(closeCh) {
+				return 0, nil, seg.ErrClosed
+			}
+
+			rv[doc.segI][doc.docNum] = newDocNum
+
+			err := writeDoc(segments[doc.segI], doc.docNum, newDocNum)
+			if err != nil {
+				return 0, nil, err
+			}
+
+			newDocNum++
+		}
+
+		storedIndexOffset, err := persistStoredIndex(docNumOffsets, w)
+		return storedIndexOffset, rv, err
+	}
+
 	// for each segment
 	for segI, segment := range segments {
 		// check for the closure in meantime
@@ -664,90 +886,7 @@ func mergeStoredAndRemap(segments []*SegmentBase, drops []*roaring.Bitmap,
 
 			segNewDocNums[docNum] = newDocNum
 
-			curr = 0
-			metaBuf.Reset()
-			data = data[:0]
-
-			posTemp := posBuf
-
-			// collect all the data
-			for i := 0; i < len(fieldsInv); i++ {
-				vals[i] = vals[i][:0]
-				typs[i] = typs[i][:0]
-				poss[i] = poss[i][:0]
-			}
-			err := segment.visitDocument(vdc, docNum, func(field string, typ byte, value []byte, pos []uint64) bool {
-				fieldID := int(fieldsMap[field]) - 1
-				vals[fieldID] = append(vals[fieldID], value)
-				typs[fieldID] = append(typs[fieldID], typ)
-
-				// copy array positions to preserve them beyond the scope of this callback
-				var curPos []uint64
-				if len(pos) > 0 {
-					if cap(posTemp) < len(pos) {
-						posBuf = make([]uint64, len(pos)*len(fieldsInv))
-						posTemp = posBuf
-					}
-					curPos = posTemp[0:len(pos)]
-					copy(curPos, pos)
-					posTemp = posTemp[len(pos):]
-				}
-				poss[fieldID] = append(poss[fieldID], curPos)
-
-				return true
-			})
-			if err != nil {
-				return 0, nil, err
-			}
-
-			// _id field special case optimizes ExternalID() lookups
-			idFieldVal := vals[uint16(0)][0]
-			_, err = metaEncode(uint64(len(idFieldVal)))
-			if err != nil {
-				return 0, nil, err
-			}
-
-			// now walk the non-"_id" fields in order
-			for fieldID := 1; fieldID < len(fieldsInv); fieldID++ {
-				storedFieldValues := vals[fieldID]
-
-				stf := typs[fieldID]
-				spf := poss[fieldID]
-
-				var err2 error
-				curr, data, err2 = persistStoredFieldValues(fieldID,
-					storedFieldValues, stf, spf, curr, metaEncode, data)
-				if err2 != nil {
-					return 0, nil, err2
-				}
-			}
-
-			metaBytes := metaBuf.Bytes()
-
-			compressed = snappy.Encode(compressed[:cap(compressed)], data)
-
-			// record where we're about to start writing
-			docNumOffsets[newDocNum] = uint64(w.Count())
-
-			// write out the meta len and compressed data len
-			_, err = writeUvarints(w,
-				uint64(len(metaBytes)),
-				uint64(len(idFieldVal)+len(compressed)))
-			if err != nil {
-				return 0, nil, err
-			}
-			// now write the meta
-			_, err = w.Write(metaBytes)
-			if err != nil {
-				return 0, nil, err
-			}
-			// now write the _id field val (counted as part of the 'compressed' data)
-			_, err = w.Write(idFieldVal)
-			if err != nil {
-				return 0, nil, err
-			}
-			// now write the compressed data
-			_, err = w.Write(compressed)
+			err := writeDoc(segment, docNum, newDocNum)
 			if err != nil {
 				return 0, nil, err
 			}
@@ -758,18 +897,23 @@ func mergeStoredAndRemap(segments []*SegmentBase, drops []*roaring.Bitmap,
 		rv = append(rv, segNewDocNums)
 	}
 
-	// return value is the start of the stored index
+	storedIndexOffset, err := persistStoredIndex(docNumOffsets, w)
+	return storedIndexOffset, rv, err
+}
+
+// persistStoredIndex writes out the stored doc index, returning its
+// starting offset
+func persistStoredIndex(docNumOffsets []uint64, w *CountHashWriter) (uint64, error) {
 	storedIndexOffset := uint64(w.Count())
 
-	// now write out the stored doc index
 	for _, docNumOffset := range docNumOffsets {
 		err := binary.Write(w, binary.BigEndian, docNumOffset)
 		if err != nil {
-			return 0, nil, err
+			return 0, err
 		}
 	}
 
-	return storedIndexOffset, rv, nil
+	return storedIndexOffset, nil
 }
 
 // copyStoredDocs writes out a segment's stored doc info, optimized by
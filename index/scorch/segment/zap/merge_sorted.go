@@ -0,0 +1,240 @@
+//  Copyright (c) 2019 Couchbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 		http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zap
+
+import (
+	"encoding/binary"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/RoaringBitmap/roaring"
+)
+
+// DocLess reports whether the document docNumI of the segment segI
+// should be ordered before the document docNumJ of the segment segJ,
+// where segI and segJ are indexes into the segments being merged.
+type DocLess func(segI int, docNumI uint64, segJ int, docNumJ uint64) bool
+
+// mergeDoc identifies a document of one of the segments being merged
+type mergeDoc struct {
+	segI   int
+	docNum uint64
+}
+
+// sortMergeDocs returns the documents which survive the merge, in the
+// order given by the less function
+func sortMergeDocs(segments []*SegmentBase, drops []*roaring.Bitmap,
+	numDocs uint64, less DocLess) []mergeDoc {
+	rv := make([]mergeDoc, 0, numDocs)
+	for segI, segment := range segments {
+		for docNum := uint64(0); docNum < segment.numDocs; docNum++ {
+			if drops[segI] != nil && drops[segI].Contains(uint32(docNum)) {
+				continue
+			}
+			rv = append(rv, mergeDoc{segI: segI, docNum: docNum})
+		}
+	}
+
+	sort.SliceStable(rv, func(i, j int) bool {
+		return less(rv[i].segI, rv[i].docNum, rv[j].segI, rv[j].docNum)
+	})
+
+	return rv
+}
+
+// bufferedPostings collects the postings of a term across the segments
+// being merged, so that they can be encoded in increasing docNum order
+// when the merge reorders the documents
+type bufferedPostings struct {
+	postings []bufferedPosting
+	locs     []byte
+	buf      []byte
+}
+
+type bufferedPosting struct {
+	docNum   uint64
+	freq     uint64
+	norm     uint64
+	locStart int
+	locEnd   int
+}
+
+func (b *bufferedPostings) Len() int {
+	return len(b.postings)
+}
+
+func (b *bufferedPostings) Less(i, j int) bool {
+	return b.postings[i].docNum < b.postings[j].docNum
+}
+
+func (b *bufferedPostings) Swap(i, j int) {
+	b.postings[i], b.postings[j] = b.postings[j], b.postings[i]
+}
+
+// add buffers the postings of the iterator, remapped to their new
+// docNums, encoding their locations like mergeTermFreqNormLocs
+func (b *bufferedPostings) add(fieldsMap map[string]uint16,
+	postItr *PostingsIterator, newDocNums []uint64, newRoaring *roaring.Bitmap,
+	bufLoc []uint64) ([]uint64, error) {
+	next, err := postItr.Next()
+	for next != nil && err == nil {
+		hitNewDocNum := newDocNums[next.Number()]
+		if hitNewDocNum == docDropped {
+			return nil, fmt.Errorf("see hit with dropped docNum")
+		}
+
+		newRoaring.Add(uint32(hitNewDocNum))
+
+		locStart := len(b.locs)
+
+		locs := next.Locations()
+		if len(locs) > 0 {
+			numBytesLocs := 0
+			for _, loc := range locs {
+				ap := loc.ArrayPositions()
+				numBytesLocs += totalUvarintBytes(uint64(fieldsMap[loc.Field()]-1),
+					loc.Pos(), loc.Start(), loc.End(), uint64(len(ap)), ap)
+			}
+
+			b.appendUvarints(uint64(numBytesLocs))
+
+			for _, loc := range locs {
+				ap := loc.ArrayPositions()
+				if cap(bufLoc) < 5+len(ap) {
+					bufLoc = make([]uint64, 0, 5+len(ap))
+				}
+				args := bufLoc[0:5]
+				args[0] = uint64(fieldsMap[loc.Field()] - 1)
+				args[1] = loc.Pos()
+				args[2] = loc.Start()
+				args[3] = loc.End()
+				args[4] = uint64(len(ap))
+				args = append(args, ap...)
+				b.appendUvarints(args...)
+			}
+		}
+
+		b.postings = append(b.postings, bufferedPosting{
+			docNum:   hitNewDocNum,
+			freq:     next.Frequency(),
+			norm:     uint64(math.Float32bits(float32(next.Norm()))),
+			locStart: locStart,
+			locEnd:   len(b.locs),
+		})
+
+		next, err = postItr.Next()
+	}
+
+	return bufLoc, err
+}
+
+func (b *bufferedPostings) appendUvarints(vals ...uint64) {
+	if len(b.buf) < binary.MaxVarintLen64 {
+		b.buf = make([]byte, binary.MaxVarintLen64)
+	}
+	for _, val := range vals {
+		wb := binary.PutUvarint(b.buf, val)
+		b.locs = append(b.locs, b.buf[:wb]...)
+	}
+}
+
+// flush encodes the buffered postings in increasing docNum order, and
+// resets the buffer for the next term
+func (b *bufferedPostings) flush(tfEncoder, locEncoder *chunkedIntCoder,
+	impacts *chunkedImpacts) (lastDocNum, lastFreq, lastNorm uint64, err error) {
+	sort.Sort(b)
+
+	for _, p := range b.postings {
+		hasLocs := p.locEnd > p.locStart
+
+		err = tfEncoder.Add(p.docNum, encodeFreqHasLocs(p.freq, hasLocs), p.norm)
+		if err != nil {
+			return 0, 0, 0, err
+		}
+
+		impacts.Add(p.docNum, p.freq, p.norm)
+
+		if hasLocs {
+			err = locEncoder.AddBytes(p.docNum, b.locs[p.locStart:p.locEnd])
+			if err != nil {
+				return 0, 0, 0, err
+			}
+		}
+
+		lastDocNum = p.docNum
+		lastFreq = p.freq
+		lastNorm = p.norm
+	}
+
+	b.postings = b.postings[:0]
+	b.locs = b.locs[:0]
+
+	return lastDocNum, lastFreq, lastNorm, nil
+}
+
+// bufferedDocValues collects the doc values of a field across the
+// segments being merged, so that they can be encoded in increasing
+// docNum order when the merge reorders the documents
+type bufferedDocValues struct {
+	docs  []bufferedDocValue
+	terms []byte
+}
+
+type bufferedDocValue struct {
+	docNum uint64
+	start  int
+	end    int
+}
+
+func (b *bufferedDocValues) Len() int {
+	return len(b.docs)
+}
+
+func (b *bufferedDocValues) Less(i, j int) bool {
+	return b.docs[i].docNum < b.docs[j].docNum
+}
+
+func (b *bufferedDocValues) Swap(i, j int) {
+	b.docs[i], b.docs[j] = b.docs[j], b.docs[i]
+}
+
+func (b *bufferedDocValues) add(docNum uint64, terms []byte) {
+	start := len(b.terms)
+	b.terms = append(b.terms, terms...)
+	b.docs = append(b.docs, bufferedDocValue{
+		docNum: docNum,
+		start:  start,
+		end:    len(b.terms),
+	})
+}
+
+// flush encodes the buffered doc values in increasing docNum order,
+// and resets the buffer for the next field
+func (b *bufferedDocValues) flush(fdvEncoder *chunkedContentCoder) error {
+	sort.Sort(b)
+
+	for _, doc := range b.docs {
+		err := fdvEncoder.Add(doc.docNum, b.terms[doc.start:doc.end])
+		if err != nil {
+			return err
+		}
+	}
+
+	b.docs = b.docs[:0]
+	b.terms = b.terms[:0]
+
+	return nil
+}
@@ -0,0 +1,170 @@
+//  Copyright (c) 2019 Couchbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 		http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package zap
+
+import (
+	"fmt"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/RoaringBitmap/roaring"
+)
+
+// reverseDocs orders the documents of the merged segments in reverse
+func reverseDocs(segI int, docNumI uint64, segJ int, docNumJ uint64) bool {
+	if segI != segJ {
+		return segI > segJ
+	}
+	return docNumI > docNumJ
+}
+
+func TestMergeSorted(t *testing.T) {
+	var segsToMerge []*Segment
+	for i, build := range []func() (*SegmentBase, error){
+		func() (*SegmentBase, error) {
+			sb, _, err := buildTestSegmentMulti()
+			return sb, err
+		},
+		func() (*SegmentBase, error) {
+			sb, _, err := buildTestSegmentMulti2()
+			return sb, err
+		},
+		func() (*SegmentBase, error) {
+			// includes doc values
+			sb, _, err := buildTestSegmentWithDefaultFieldMapping(1024)
+			return sb, err
+		},
+	} {
+		fname := fmt.Sprintf("/tmp/scorch-sorted-%d.zap", i)
+		_ = os.RemoveAll(fname)
+
+		testSeg, err := build()
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = PersistSegmentBase(testSeg, fname)
+		if err != nil {
+			t.Fatal(err)
+		}
+		segment, err := Open(fname)
+		if err != nil {
+			t.Fatalf("error opening segment: %v", err)
+		}
+		defer func(segment *Segment) {
+			cerr := segment.Close()
+			if cerr != nil {
+				t.Fatalf("error closing segment: %v", cerr)
+			}
+		}(segment.(*Segment))
+
+		segsToMerge = append(segsToMerge, segment.(*Segment))
+	}
+	drops := make([]*roaring.Bitmap, len(segsToMerge))
+
+	openMerged := func(fname string) *Segment {
+		segm, err := Open(fname)
+		if err != nil {
+			t.Fatalf("error opening merged segment: %v", err)
+		}
+		return segm.(*Segment)
+	}
+
+	_ = os.RemoveAll("/tmp/scorch-merged.zap")
+	_, _, err := Merge(segsToMerge, drops, "/tmp/scorch-merged.zap", 1, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	merged := openMerged("/tmp/scorch-merged.zap")
+	defer func() {
+		_ = merged.Close()
+	}()
+
+	// reversing the documents...
+	_ = os.RemoveAll("/tmp/scorch-reversed.zap")
+	newDocNums, _, err := MergeSorted(segsToMerge, drops,
+		"/tmp/scorch-reversed.zap", 1, reverseDocs, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reversed := openMerged("/tmp/scorch-reversed.zap")
+	defer func() {
+		_ = reversed.Close()
+	}()
+
+	if newDocNums[0][0] != reversed.Count()-1 {
+		t.Errorf("expected first doc to become %d, got %d",
+			reversed.Count()-1, newDocNums[0][0])
+	}
+	for docNum := uint64(0); docNum < merged.Count(); docNum++ {
+		expectedID, err := merged.DocID(docNum)
+		if err != nil {
+			t.Fatal(err)
+		}
+		id, err := reversed.DocID(reversed.Count() - 1 - docNum)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if string(id) != string(expectedID) {
+			t.Errorf("expected doc %d to be %s, got %s",
+				reversed.Count()-1-docNum, expectedID, id)
+		}
+	}
+
+	// ...twice, gives back the plain merge
+	_ = os.RemoveAll("/tmp/scorch-reversed2.zap")
+	_, _, err = MergeSorted([]*Segment{reversed}, drops[:1],
+		"/tmp/scorch-reversed2.zap", 1, reverseDocs, nil, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	reversed2 := openMerged("/tmp/scorch-reversed2.zap")
+	defer func() {
+		_ = reversed2.Close()
+	}()
+
+	diff := compareSegments(merged, reversed2)
+	if diff != "" {
+		t.Errorf("sorted merges differ from the plain merge: %s", diff)
+	}
+
+	fields, err := merged.VisitableDocValueFields()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(fields) == 0 {
+		t.Fatalf("expected doc value fields")
+	}
+	for docNum := uint64(0); docNum < merged.Count(); docNum++ {
+		var expected, actual []string
+		_, err = merged.VisitDocumentFieldTerms(docNum, fields,
+			func(field string, term []byte) {
+				expected = append(expected, field+":"+string(term))
+			}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		_, err = reversed2.VisitDocumentFieldTerms(docNum, fields,
+			func(field string, term []byte) {
+				actual = append(actual, field+":"+string(term))
+			}, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !reflect.DeepEqual(expected, actual) {
+			t.Errorf("doc %d, expected doc values %v, got %v", docNum, expected, actual)
+		}
+	}
+}
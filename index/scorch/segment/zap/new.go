@@ -128,6 +128,14 @@ type interim struct {
 	tmp0 []byte
 	tmp1 []byte
 
+	// field id -> stored values of the current doc, an arena whose
+	// slices are reused from doc to doc and from segment to segment
+	storedFields []interimStoredField
+
+	// field id -> length and token frequencies of the current doc
+	fieldLens []int
+	fieldTFs  []analysis.TokenFrequencies
+
 	lastNumDocs int
 	lastOutSize int
 }
@@ -136,10 +144,14 @@ func (s *interim) reset() (err error) {
 	s.results = nil
 	s.chunkFactor = 0
 	s.w = nil
-	s.FieldsMap = nil
-	s.FieldsInv = nil
-	for i := range s.Dicts {
-		s.Dicts[i] = nil
+	for fieldName := range s.FieldsMap {
+		delete(s.FieldsMap, fieldName)
+	}
+	s.FieldsInv = s.FieldsInv[:0]
+	for _, dict := range s.Dicts {
+		for term := range dict {
+			delete(dict, term)
+		}
 	}
 	s.Dicts = s.Dicts[:0]
 	for i := range s.DictKeys {
@@ -173,6 +185,10 @@ func (s *interim) reset() (err error) {
 	s.metaBuf.Reset()
 	s.tmp0 = s.tmp0[:0]
 	s.tmp1 = s.tmp1[:0]
+	for i := range s.storedFields {
+		s.storedFields[i].reset()
+	}
+	s.storedFields = s.storedFields[:0]
 	s.lastNumDocs = 0
 	s.lastOutSize = 0
 
@@ -194,6 +210,16 @@ type interimStoredField struct {
 	arrayposs [][]uint64 // array positions
 }
 
+func (isf *interimStoredField) reset() {
+	for i := range isf.vals {
+		isf.vals[i] = nil
+		isf.arrayposs[i] = nil
+	}
+	isf.vals = isf.vals[:0]
+	isf.typs = isf.typs[:0]
+	isf.arrayposs = isf.arrayposs[:0]
+}
+
 type interimFreqNorm struct {
 	freq    uint64
 	norm    float32
@@ -209,7 +235,9 @@ type interimLoc struct {
 }
 
 func (s *interim) convert() (uint64, uint64, uint64, []uint64, error) {
-	s.FieldsMap = map[string]uint16{}
+	if s.FieldsMap == nil {
+		s.FieldsMap = map[string]uint16{}
+	}
 
 	s.getOrDefineField("_id") // _id field is fieldID 0
 
@@ -274,9 +302,14 @@ func (s *interim) getOrDefineField(fieldName string) int {
 		s.FieldsMap[fieldName] = fieldIDPlus1
 		s.FieldsInv = append(s.FieldsInv, fieldName)
 
-		s.Dicts = append(s.Dicts, make(map[string]uint64))
+		n := len(s.Dicts)
+		if n < cap(s.Dicts) && s.Dicts[:n+1][n] != nil {
+			s.Dicts = s.Dicts[:n+1]
+		} else {
+			s.Dicts = append(s.Dicts, make(map[string]uint64))
+		}
 
-		n := len(s.DictKeys)
+		n = len(s.DictKeys)
 		if n < cap(s.DictKeys) {
 			s.DictKeys = s.DictKeys[:n+1]
 			s.DictKeys[n] = s.DictKeys[n][:0]
@@ -395,8 +428,14 @@ func (s *interim) prepareDicts() {
 
 func (s *interim) processDocuments() {
 	numFields := len(s.FieldsInv)
-	reuseFieldLens := make([]int, numFields)
-	reuseFieldTFs := make([]analysis.TokenFrequencies, numFields)
+	if cap(s.fieldLens) >= numFields {
+		s.fieldLens = s.fieldLens[:numFields]
+		s.fieldTFs = s.fieldTFs[:numFields]
+	} else {
+		s.fieldLens = make([]int, numFields)
+		s.fieldTFs = make([]analysis.TokenFrequencies, numFields)
+	}
+	reuseFieldLens, reuseFieldTFs := s.fieldLens, s.fieldTFs
 
 	for docNum, result := range s.results {
 		for i := 0; i < numFields; i++ { // clear these for reuse
@@ -407,6 +446,10 @@ func (s *interim) processDocuments() {
 		s.processDocument(uint64(docNum), result,
 			reuseFieldLens, reuseFieldTFs)
 	}
+
+	for i := range reuseFieldTFs {
+		reuseFieldTFs[i] = nil
+	}
 }
 
 func (s *interim) processDocument(docNum uint64,
@@ -498,11 +541,17 @@ func (s *interim) writeStoredFields() (
 	docStoredOffsets := make([]uint64, len(s.results))
 
 	// keyed by fieldID, for the current doc in the loop
-	docStoredFields := map[uint16]interimStoredField{}
+	if cap(s.storedFields) >= len(s.FieldsInv) {
+		s.storedFields = s.storedFields[:len(s.FieldsInv)]
+	} else {
+		s.storedFields = append(s.storedFields[:cap(s.storedFields)],
+			make([]interimStoredField, len(s.FieldsInv)-cap(s.storedFields))...)
+	}
+	docStoredFields := s.storedFields
 
 	for docNum, result := range s.results {
 		for fieldID := range docStoredFields { // reset for next doc
-			delete(docStoredFields, fieldID)
+			docStoredFields[fieldID].reset()
 		}
 
 		for _, field := range result.Document.Fields {
@@ -511,11 +560,10 @@ func (s *interim) writeStoredFields() (
 			opts := field.Options()
 
 			if opts.IsStored() {
-				isf := docStoredFields[fieldID]
+				isf := &docStoredFields[fieldID]
 				isf.vals = append(isf.vals, field.Value())
 				isf.typs = append(isf.typs, encodeFieldType(field))
 				isf.arrayposs = append(isf.arrayposs, field.ArrayPositions())
-				docStoredFields[fieldID] = isf
 			}
 
 			if opts.IncludeDocValues() {
@@ -537,8 +585,8 @@ func (s *interim) writeStoredFields() (
 
 		// handle non-"_id" fields
 		for fieldID := 1; fieldID < len(s.FieldsInv); fieldID++ {
-			isf, exists := docStoredFields[uint16(fieldID)]
-			if exists {
+			isf := &docStoredFields[fieldID]
+			if len(isf.vals) > 0 {
 				curr, data, err = persistStoredFieldValues(
 					fieldID, isf.vals, isf.typs, isf.arrayposs,
 					curr, metaEncode, data)
@@ -599,6 +647,7 @@ func (s *interim) writeDicts() (fdvIndexOffset uint64, dictOffsets []uint64, err
 
 	tfEncoder := newChunkedIntCoder(uint64(s.chunkFactor), uint64(len(s.results)-1))
 	locEncoder := newChunkedIntCoder(uint64(s.chunkFactor), uint64(len(s.results)-1))
+	impacts := newChunkedImpacts(uint64(s.chunkFactor), uint64(len(s.results)-1))
 	fdvEncoder := newChunkedContentCoder(uint64(s.chunkFactor), uint64(len(s.results)-1), s.w, false)
 
 	var docTermMap [][]byte
@@ -639,13 +688,17 @@ func (s *interim) writeDicts() (fdvIndexOffset uint64, dictOffsets []uint64, err
 
 				freqNorm := freqNorms[freqNormOffset]
 
+				normBits := uint64(math.Float32bits(freqNorm.norm))
+
 				err = tfEncoder.Add(docNum,
 					encodeFreqHasLocs(freqNorm.freq, freqNorm.numLocs > 0),
-					uint64(math.Float32bits(freqNorm.norm)))
+					normBits)
 				if err != nil {
 					return 0, nil, err
 				}
 
+				impacts.Add(docNum, freqNorm.freq, normBits)
+
 				if freqNorm.numLocs > 0 {
 					numBytesLocs := 0
 					for _, loc := range locs[locOffset : locOffset+freqNorm.numLocs] {
@@ -687,7 +740,7 @@ func (s *interim) writeDicts() (fdvIndexOffset uint64, dictOffsets []uint64, err
 			locEncoder.Close()
 
 			postingsOffset, err :=
-				writePostings(postingsBS, tfEncoder, locEncoder, nil, s.w, buf)
+				writePostings(postingsBS, tfEncoder, locEncoder, impacts, nil, s.w, buf)
 			if err != nil {
 				return 0, nil, err
 			}
@@ -701,6 +754,7 @@ func (s *interim) writeDicts() (fdvIndexOffset uint64, dictOffsets []uint64, err
 
 			tfEncoder.Reset()
 			locEncoder.Reset()
+			impacts.Reset()
 		}
 
 		err = s.builder.Close()
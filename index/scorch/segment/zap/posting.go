@@ -100,6 +100,7 @@ type PostingsList struct {
 	postingsOffset uint64
 	freqOffset     uint64
 	locOffset      uint64
+	impactsOffset  uint64
 	postings       *roaring.Bitmap
 	except         *roaring.Bitmap
 
@@ -173,6 +174,8 @@ func (p *PostingsList) iterator(includeFreq, includeNorm, includeLocs bool,
 		nextLocs := rv.nextLocs[:0]
 		nextSegmentLocs := rv.nextSegmentLocs[:0]
 
+		impacts := rv.impacts[:0]
+
 		buf := rv.buf
 
 		*rv = PostingsIterator{} // clear the struct
@@ -186,6 +189,8 @@ func (p *PostingsList) iterator(includeFreq, includeNorm, includeLocs bool,
 		rv.nextLocs = nextLocs
 		rv.nextSegmentLocs = nextSegmentLocs
 
+		rv.impacts = impacts
+
 		rv.buf = buf
 	}
 
@@ -295,6 +300,9 @@ func (rv *PostingsList) read(postingsOffset uint64, d *Dictionary) error {
 	rv.locOffset, read = binary.Uvarint(d.sb.mem[postingsOffset+n : postingsOffset+n+binary.MaxVarintLen64])
 	n += uint64(read)
 
+	rv.impactsOffset, read = binary.Uvarint(d.sb.mem[postingsOffset+n : postingsOffset+n+binary.MaxVarintLen64])
+	n += uint64(read)
+
 	var postingsLen uint64
 	postingsLen, read = binary.Uvarint(d.sb.mem[postingsOffset+n : postingsOffset+n+binary.MaxVarintLen64])
 	n += uint64(read)
@@ -348,6 +356,9 @@ type PostingsIterator struct {
 	docNum1Hit   uint64
 	normBits1Hit uint64
 
+	impacts       []float32 // lazily loaded, see loadImpacts()
+	impactsLoaded bool
+
 	buf []byte
 
 	includeFreqNorm bool
@@ -362,6 +373,7 @@ func (i *PostingsIterator) Size() int {
 		len(i.currChunkLoc) +
 		len(i.freqChunkOffsets)*size.SizeOfUint64 +
 		len(i.locChunkOffsets)*size.SizeOfUint64 +
+		len(i.impacts)*size.SizeOfFloat32 +
 		i.next.Size()
 
 	for _, entry := range i.nextLocs {
@@ -787,6 +799,60 @@ func (i *PostingsIterator) currChunkNext(nChunk uint32) error {
 	return nil
 }
 
+func (i *PostingsIterator) loadImpacts() bool {
+	if i.postings == nil || i.postings.sb == nil || i.postings.postings == nil {
+		return false
+	}
+	if !i.impactsLoaded {
+		i.impacts = i.postings.sb.readImpacts(i.postings.impactsOffset, i.impacts)
+		i.impactsLoaded = true
+	}
+	return true
+}
+
+// MaxImpact returns an upper bound on the impact, sqrt(freq) * norm,
+// of the postings in this iterator.
+func (i *PostingsIterator) MaxImpact() float64 {
+	if i.normBits1Hit != 0 {
+		return float64(postingImpact(1, math.Float32frombits(uint32(i.normBits1Hit))))
+	}
+	if !i.loadImpacts() {
+		if i.ActualBM == nil {
+			return 0 // empty iterator
+		}
+		return math.Inf(1) // no impacts available
+	}
+	var rv float32
+	for _, impact := range i.impacts {
+		if impact > rv {
+			rv = impact
+		}
+	}
+	return float64(rv)
+}
+
+// BlockMaxImpact returns an upper bound on the impact of the postings
+// in the chunk containing the docNum, along with the docNum where the
+// following chunk starts.
+func (i *PostingsIterator) BlockMaxImpact(docNum uint64) (float64, uint64) {
+	if i.normBits1Hit != 0 {
+		return float64(postingImpact(1, math.Float32frombits(uint32(i.normBits1Hit)))),
+			math.MaxUint64
+	}
+	if !i.loadImpacts() {
+		if i.ActualBM == nil {
+			return 0, math.MaxUint64
+		}
+		return math.Inf(1), math.MaxUint64
+	}
+	chunkFactor := uint64(i.postings.sb.chunkFactor)
+	chunk := docNum / chunkFactor
+	if chunk >= uint64(len(i.impacts)) {
+		return 0, math.MaxUint64
+	}
+	return float64(i.impacts[chunk]), (chunk + 1) * chunkFactor
+}
+
 // DocNum1Hit returns the docNum and true if this is "1-hit" optimized
 // and the docNum is available.
 func (p *PostingsIterator) DocNum1Hit() (uint64, bool) {
@@ -192,6 +192,22 @@ func (i *IndexSnapshot) FieldDictPrefix(field string,
 	})
 }
 
+func (i *IndexSnapshot) FieldDictBounded(field string,
+	bounds index.FieldDictBounds) (index.FieldDict, error) {
+	start, end := bounds.KeyRange()
+	empty := bounds.Empty()
+	rv, err := i.newIndexSnapshotFieldDict(field, func(i segment.TermDictionary) segment.DictionaryIterator {
+		if empty {
+			return &segment.EmptyDictionaryIterator{}
+		}
+		return i.AutomatonIterator(&vellum.AlwaysMatch{}, start, end)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return index.NewLimitedFieldDict(rv, bounds.Limit), nil
+}
+
 func (i *IndexSnapshot) FieldDictRegexp(field string,
 	termRegex string) (index.FieldDict, error) {
 	// TODO: potential optimization where the literal prefix represents the,
@@ -396,6 +412,28 @@ func (i *IndexSnapshot) segmentIndexAndLocalDocNumFromGlobal(docNum uint64) (int
 	return int(segmentIndex), localDocNum
 }
 
+// SegmentSort returns the field the documents of each segment are
+// ordered by, per the indexSort config of the index
+func (i *IndexSnapshot) SegmentSort() (string, bool) {
+	if i.parent == nil || i.parent.indexSort == nil {
+		return "", false
+	}
+	return i.parent.indexSort.field, i.parent.indexSort.desc
+}
+
+func (i *IndexSnapshot) NextSegmentStart(id index.IndexInternalID) (
+	index.IndexInternalID, error) {
+	docNum, err := docInternalToNumber(id)
+	if err != nil {
+		return nil, err
+	}
+	segmentIndex, _ := i.segmentIndexAndLocalDocNumFromGlobal(docNum)
+	if segmentIndex+1 >= len(i.offsets) {
+		return nil, nil
+	}
+	return docNumberToBytes(nil, i.offsets[segmentIndex+1]), nil
+}
+
 func (i *IndexSnapshot) ExternalID(id index.IndexInternalID) (string, error) {
 	docNum, err := docInternalToNumber(id)
 	if err != nil {
@@ -453,6 +491,7 @@ func (i *IndexSnapshot) TermFieldReader(term []byte, field string, includeFreq,
 	rv.includeTermVectors = includeTermVectors
 	rv.currPosting = nil
 	rv.currID = rv.currID[:0]
+	rv.count = 0
 
 	if rv.dicts == nil {
 		rv.dicts = make([]segment.TermDictionary, len(i.segment))
@@ -651,6 +690,53 @@ func (dvr *DocValueReader) VisitDocValues(id index.IndexInternalID,
 	return err
 }
 
+// DocValueSummaryReader returns a reader of the summaries of the terms
+// of the given fields, which are memoized per segment
+func (i *IndexSnapshot) DocValueSummaryReader(fields []string) (
+	index.DocValueSummaryReader, error) {
+	return &DocValueSummaryReader{i: i, fields: fields, currSegmentIndex: -1}, nil
+}
+
+type DocValueSummaryReader struct {
+	i       *IndexSnapshot
+	fields  []string
+	summary index.DocValueSummary
+
+	currSegmentIndex int
+	currSummaries    []*cachedFieldSummaries
+}
+
+func (dvsr *DocValueSummaryReader) VisitDocValueSummaries(id index.IndexInternalID,
+	visitor index.DocValueSummaryVisitor) (err error) {
+	docNum, err := docInternalToNumber(id)
+	if err != nil {
+		return err
+	}
+
+	segmentIndex, localDocNum := dvsr.i.segmentIndexAndLocalDocNumFromGlobal(docNum)
+	if segmentIndex >= len(dvsr.i.segment) {
+		return nil
+	}
+
+	if dvsr.currSegmentIndex != segmentIndex {
+		ss := dvsr.i.segment[segmentIndex]
+		dvsr.currSummaries, err = ss.cachedDocs.prepareSummaries(dvsr.fields, ss)
+		if err != nil {
+			dvsr.currSegmentIndex = -1
+			return err
+		}
+		dvsr.currSegmentIndex = segmentIndex
+	}
+
+	for i, cfs := range dvsr.currSummaries {
+		if cfs.summarize(localDocNum, &dvsr.summary) {
+			visitor(dvsr.fields[i], &dvsr.summary)
+		}
+	}
+
+	return nil
+}
+
 func (i *IndexSnapshot) DumpAll() chan interface{} {
 	rv := make(chan interface{})
 	go func() {
@@ -0,0 +1,163 @@
+//  Copyright (c) 2019 Couchbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 		http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorch
+
+import (
+	"container/heap"
+	"sync/atomic"
+
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/index/scorch/segment"
+)
+
+type segmentAutomatonCursor struct {
+	segmentIndex int
+	dict         segment.TermDictionary
+	itr          segment.DictionaryIterator
+	term         string
+}
+
+// IndexSnapshotAutomatonTerms merges the terms accepted by an automaton
+// in the dictionaries of the segments, reading the postings of each
+// term from the dictionary iterators positioned on it.
+type IndexSnapshotAutomatonTerms struct {
+	snapshot           *IndexSnapshot
+	field              string
+	includeFreq        bool
+	includeNorm        bool
+	includeTermVectors bool
+	cursors            []*segmentAutomatonCursor
+}
+
+func (i *IndexSnapshotAutomatonTerms) Len() int { return len(i.cursors) }
+func (i *IndexSnapshotAutomatonTerms) Less(a, b int) bool {
+	if i.cursors[a].term == i.cursors[b].term {
+		return i.cursors[a].segmentIndex < i.cursors[b].segmentIndex
+	}
+	return i.cursors[a].term < i.cursors[b].term
+}
+func (i *IndexSnapshotAutomatonTerms) Swap(a, b int) {
+	i.cursors[a], i.cursors[b] = i.cursors[b], i.cursors[a]
+}
+
+func (i *IndexSnapshotAutomatonTerms) Push(x interface{}) {
+	i.cursors = append(i.cursors, x.(*segmentAutomatonCursor))
+}
+
+func (i *IndexSnapshotAutomatonTerms) Pop() interface{} {
+	n := len(i.cursors)
+	x := i.cursors[n-1]
+	i.cursors = i.cursors[0 : n-1]
+	return x
+}
+
+func (i *IndexSnapshot) AutomatonTerms(field string, a index.Automaton,
+	startKeyInclusive, endKeyExclusive []byte,
+	includeFreq, includeNorm, includeTermVectors bool) (
+	index.AutomatonTermIterator, error) {
+	rv := &IndexSnapshotAutomatonTerms{
+		snapshot:           i,
+		field:              field,
+		includeFreq:        includeFreq,
+		includeNorm:        includeNorm,
+		includeTermVectors: includeTermVectors,
+		cursors:            make([]*segmentAutomatonCursor, 0, len(i.segment)),
+	}
+	for segmentIndex, ss := range i.segment {
+		dict, err := ss.segment.Dictionary(field)
+		if err != nil {
+			return nil, err
+		}
+		itr := dict.AutomatonIterator(a, startKeyInclusive, endKeyExclusive)
+		next, err := itr.Next()
+		if err != nil {
+			return nil, err
+		}
+		if next != nil {
+			rv.cursors = append(rv.cursors, &segmentAutomatonCursor{
+				segmentIndex: segmentIndex,
+				dict:         dict,
+				itr:          itr,
+				term:         next.Term,
+			})
+		}
+	}
+	heap.Init(rv)
+	return rv, nil
+}
+
+func (i *IndexSnapshotAutomatonTerms) Next() ([]byte, index.TermFieldReader, error) {
+	if len(i.cursors) == 0 {
+		return nil, nil, nil
+	}
+	term := []byte(i.cursors[0].term)
+
+	segments := i.snapshot.segment
+	rv := &IndexSnapshotTermFieldReader{
+		term:               term,
+		field:              i.field,
+		snapshot:           i.snapshot,
+		postings:           make([]segment.PostingsList, len(segments)),
+		iterators:          make([]segment.PostingsIterator, len(segments)),
+		includeFreq:        i.includeFreq,
+		includeNorm:        i.includeNorm,
+		includeTermVectors: i.includeTermVectors,
+	}
+	for len(i.cursors) > 0 && i.cursors[0].term == string(term) {
+		cursor := i.cursors[0]
+		except := segments[cursor.segmentIndex].deleted
+
+		var pl segment.PostingsList
+		var err error
+		if itrPostings, ok := cursor.itr.(segment.DictionaryIteratorPostings); ok {
+			pl, err = itrPostings.PostingsList(except, nil)
+		} else {
+			pl, err = cursor.dict.PostingsList(term, except, nil)
+		}
+		if err != nil {
+			return nil, nil, err
+		}
+		rv.postings[cursor.segmentIndex] = pl
+
+		next, err := cursor.itr.Next()
+		if err != nil {
+			return nil, nil, err
+		}
+		if next == nil {
+			// at end of this cursor, remove it
+			heap.Pop(i)
+		} else {
+			// modified heap, fix it
+			cursor.term = next.Term
+			heap.Fix(i, 0)
+		}
+	}
+
+	for segmentIndex, pl := range rv.postings {
+		if pl == nil {
+			pl = &segment.EmptyPostingsList{}
+			rv.postings[segmentIndex] = pl
+		}
+		rv.iterators[segmentIndex] = pl.Iterator(i.includeFreq, i.includeNorm,
+			i.includeTermVectors, nil)
+	}
+
+	atomic.AddUint64(&i.snapshot.parent.stats.TotTermSearchersStarted, uint64(1))
+	return term, rv, nil
+}
+
+func (i *IndexSnapshotAutomatonTerms) Close() error {
+	return nil
+}
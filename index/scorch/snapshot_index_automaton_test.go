@@ -0,0 +1,148 @@
+//  Copyright (c) 2019 Couchbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 		http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package scorch
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/blevesearch/bleve/document"
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/index/scorch/segment"
+)
+
+func TestIndexAutomatonTerms(t *testing.T) {
+	cfg := CreateConfig("TestIndexAutomatonTerms")
+	err := InitTest(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := DestroyTest(cfg)
+		if err != nil {
+			t.Log(err)
+		}
+	}()
+
+	analysisQueue := index.NewAnalysisQueue(1)
+	idx, err := NewScorch(Name, cfg, analysisQueue)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = idx.Open()
+	if err != nil {
+		t.Fatalf("error opening index: %v", err)
+	}
+	defer func() {
+		cerr := idx.Close()
+		if cerr != nil {
+			t.Fatal(cerr)
+		}
+	}()
+
+	// each update lands in its own segment
+	docs := map[string]string{
+		"1": "bob cat dog",
+		"2": "cats catting zoo",
+		"3": "cat doggy",
+	}
+	for _, id := range []string{"1", "2", "3"} {
+		doc := document.NewDocument(id)
+		doc.AddField(document.NewTextFieldWithAnalyzer("desc", []uint64{},
+			[]byte(docs[id]), testAnalyzer))
+		err = idx.Update(doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = idx.Delete("3")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	indexReader, err := idx.Reader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := indexReader.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	a, prefixBeg, prefixEnd, err := segment.ParseRegexp("cat.*|dog.*")
+	if err != nil {
+		t.Fatal(err)
+	}
+	itr, err := indexReader.(index.IndexReaderAutomaton).AutomatonTerms("desc",
+		a, prefixBeg, prefixEnd, true, false, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := itr.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	actual := map[string][]string{}
+	var terms []string
+	term, tfr, err := itr.Next()
+	for err == nil && tfr != nil {
+		terms = append(terms, string(term))
+		var ids []string
+		tfd, err := tfr.Next(nil)
+		for err == nil && tfd != nil {
+			id, err := indexReader.ExternalID(tfd.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			ids = append(ids, id)
+			tfd, err = tfr.Next(nil)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		if tfr.Count() != uint64(len(ids)) {
+			t.Errorf("%s: expected count %d, got %d", term, len(ids), tfr.Count())
+		}
+		err = tfr.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		actual[string(term)] = ids
+		term, tfr, err = itr.Next()
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expectedTerms := []string{"cat", "cats", "catting", "dog", "doggy"}
+	if !reflect.DeepEqual(terms, expectedTerms) {
+		t.Errorf("expected terms %v, got %v", expectedTerms, terms)
+	}
+	expected := map[string][]string{
+		"cat":     {"1"},
+		"cats":    {"2"},
+		"catting": {"2"},
+		"dog":     {"1"},
+		"doggy":   nil,
+	}
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected postings %v, got %v", expected, actual)
+	}
+}
@@ -17,6 +17,7 @@ package scorch
 import (
 	"bytes"
 	"fmt"
+	"math"
 	"reflect"
 	"sync/atomic"
 
@@ -45,6 +46,7 @@ type IndexSnapshotTermFieldReader struct {
 	includeTermVectors bool
 	currPosting        segment.Posting
 	currID             index.IndexInternalID
+	count              uint64 // of the documents not from postings
 }
 
 func (i *IndexSnapshotTermFieldReader) Size() int {
@@ -169,13 +171,54 @@ func (i *IndexSnapshotTermFieldReader) Advance(ID index.IndexInternalID, preAllo
 }
 
 func (i *IndexSnapshotTermFieldReader) Count() uint64 {
-	var rv uint64
+	rv := i.count
 	for _, posting := range i.postings {
 		rv += posting.Count()
 	}
 	return rv
 }
 
+func (i *IndexSnapshotTermFieldReader) MaxImpact() float64 {
+	var rv float64
+	for _, iterator := range i.iterators[i.segmentOffset:] {
+		impacts, ok := iterator.(segment.PostingsIteratorImpacts)
+		if !ok {
+			return math.Inf(1)
+		}
+		if impact := impacts.MaxImpact(); impact > rv {
+			rv = impact
+		}
+	}
+	return rv
+}
+
+func (i *IndexSnapshotTermFieldReader) BlockMaxImpact(ID index.IndexInternalID) (
+	float64, index.IndexInternalID, error) {
+	num, err := docInternalToNumber(ID)
+	if err != nil {
+		return 0, nil, fmt.Errorf("error converting to doc number % x - %v", ID, err)
+	}
+	segIndex, ldocNum := i.snapshot.segmentIndexAndLocalDocNumFromGlobal(num)
+	if segIndex >= len(i.iterators) {
+		return 0, nil, fmt.Errorf("computed segment index %d out of bounds %d",
+			segIndex, len(i.iterators))
+	}
+	impacts, ok := i.iterators[segIndex].(segment.PostingsIteratorImpacts)
+	if !ok {
+		return math.Inf(1), nil, nil
+	}
+	impact, nextBlockStart := impacts.BlockMaxImpact(ldocNum)
+	if nextBlockStart < i.snapshot.segment[segIndex].segment.Count() {
+		return impact, docNumberToBytes(nil,
+			nextBlockStart+i.snapshot.offsets[segIndex]), nil
+	}
+	// the block extends to the end of the segment
+	if segIndex+1 < len(i.snapshot.offsets) {
+		return impact, docNumberToBytes(nil, i.snapshot.offsets[segIndex+1]), nil
+	}
+	return impact, nil, nil
+}
+
 func (i *IndexSnapshotTermFieldReader) Close() error {
 	if i.snapshot != nil {
 		atomic.AddUint64(&i.snapshot.parent.stats.TotTermSearchersFinished, uint64(1))
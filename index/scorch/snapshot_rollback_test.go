@@ -64,7 +64,7 @@ func TestIndexRollback(t *testing.T) {
 	// start background goroutines except for the merger, which
 	// simulates a super slow merger
 	sh.asyncTasks.Add(2)
-	go sh.mainLoop()
+	go sh.introducerLoop()
 	go sh.persisterLoop()
 
 	// should have no rollback points initially
@@ -22,6 +22,7 @@ import (
 	"github.com/RoaringBitmap/roaring"
 	"github.com/blevesearch/bleve/index"
 	"github.com/blevesearch/bleve/index/scorch/segment"
+	"github.com/blevesearch/bleve/numeric"
 	"github.com/blevesearch/bleve/size"
 )
 
@@ -182,10 +183,173 @@ func (cfd *cachedFieldDocs) prepareField(field string, ss *SegmentSnapshot) {
 	}
 }
 
+// cachedFieldSummaries memoizes the summaries of the terms of a field
+// for the documents of a segment, as ordinals into the terms of the
+// field which are part of a summary.
+type cachedFieldSummaries struct {
+	readyCh chan struct{}  // closed when the cachedFieldSummaries.docs is ready to be used.
+	err     error          // Non-nil if there was an error when preparing this cachedFieldSummaries.
+	terms   [][]byte       // Keyed by ordinal, in increasing order.
+	docs    []termOrdinals // Keyed by localDocNum.
+	size    uint64
+}
+
+// termOrdinals are the ordinals of the terms summarizing the terms of
+// a field of a document, or -1 when there is no such term
+type termOrdinals struct {
+	first, last                   int32
+	firstShiftZero, lastShiftZero int32
+	notAllPrefixCoded             bool
+}
+
+var reflectStaticSizeTermOrdinals = 4*4 + 4 /* int32's, bool and padding */
+
+func (cfs *cachedFieldSummaries) prepareField(field string, ss *SegmentSnapshot) {
+	defer close(cfs.readyCh)
+
+	cfs.docs = make([]termOrdinals, ss.segment.Count())
+	for i := range cfs.docs {
+		cfs.docs[i] = termOrdinals{-1, -1, -1, -1, false}
+	}
+
+	dict, err := ss.segment.Dictionary(field)
+	if err != nil {
+		cfs.err = err
+		return
+	}
+
+	var postings segment.PostingsList
+	var postingsItr segment.PostingsIterator
+
+	// the terms are visited in increasing order, so the last ordinals
+	// get overwritten until the last term of each document
+	dictItr := dict.Iterator()
+	next, err := dictItr.Next()
+	for err == nil && next != nil {
+		term := []byte(next.Term)
+		ord := int32(len(cfs.terms))
+		cfs.terms = append(cfs.terms, term)
+		valid, shift := numeric.ValidPrefixCodedTermBytes(term)
+
+		var err1 error
+		postings, err1 = dict.PostingsList(term, nil, postings)
+		if err1 != nil {
+			cfs.err = err1
+			return
+		}
+
+		postingsItr = postings.Iterator(false, false, false, postingsItr)
+		nextPosting, err2 := postingsItr.Next()
+		for err2 == nil && nextPosting != nil {
+			d := &cfs.docs[nextPosting.Number()]
+			if d.first < 0 {
+				d.first = ord
+			}
+			d.last = ord
+			if valid && shift == 0 {
+				if d.firstShiftZero < 0 {
+					d.firstShiftZero = ord
+				}
+				d.lastShiftZero = ord
+			} else if !valid {
+				d.notAllPrefixCoded = true
+			}
+			nextPosting, err2 = postingsItr.Next()
+		}
+
+		if err2 != nil {
+			cfs.err = err2
+			return
+		}
+
+		next, err = dictItr.Next()
+	}
+
+	if err != nil {
+		cfs.err = err
+		return
+	}
+
+	cfs.compact()
+}
+
+// compact drops the terms which are not part of any summary
+func (cfs *cachedFieldSummaries) compact() {
+	ords := make([]int32, len(cfs.terms))
+	mark := func(ord int32) {
+		if ord >= 0 {
+			ords[ord] = 1
+		}
+	}
+	for _, d := range cfs.docs {
+		mark(d.first)
+		mark(d.last)
+		mark(d.firstShiftZero)
+		mark(d.lastShiftZero)
+	}
+
+	terms := cfs.terms[:0]
+	for ord, used := range ords {
+		if used == 0 {
+			continue
+		}
+		ords[ord] = int32(len(terms))
+		terms = append(terms, cfs.terms[ord])
+		cfs.size += uint64(len(cfs.terms[ord]) + size.SizeOfSlice)
+	}
+	for i := len(terms); i < len(cfs.terms); i++ {
+		cfs.terms[i] = nil
+	}
+	cfs.terms = terms
+
+	remap := func(ord int32) int32 {
+		if ord < 0 {
+			return ord
+		}
+		return ords[ord]
+	}
+	for i := range cfs.docs {
+		d := &cfs.docs[i]
+		d.first = remap(d.first)
+		d.last = remap(d.last)
+		d.firstShiftZero = remap(d.firstShiftZero)
+		d.lastShiftZero = remap(d.lastShiftZero)
+	}
+	cfs.size += uint64(len(cfs.docs) * reflectStaticSizeTermOrdinals)
+}
+
+func (cfs *cachedFieldSummaries) term(ord int32) []byte {
+	if ord < 0 {
+		return nil
+	}
+	return cfs.terms[ord]
+}
+
+// summarize fills in the summary of the document, returning false if
+// the document has no terms
+func (cfs *cachedFieldSummaries) summarize(localDocNum uint64,
+	rv *index.DocValueSummary) bool {
+	if localDocNum >= uint64(len(cfs.docs)) {
+		return false
+	}
+	d := &cfs.docs[localDocNum]
+	if d.first < 0 {
+		return false
+	}
+	rv.First = cfs.term(d.first)
+	rv.Last = cfs.term(d.last)
+	rv.FirstShiftZero = cfs.term(d.firstShiftZero)
+	rv.LastShiftZero = cfs.term(d.lastShiftZero)
+	rv.AllPrefixCoded = !d.notAllPrefixCoded
+	return true
+}
+
 type cachedDocs struct {
-	m     sync.Mutex                  // As the cache is asynchronously prepared, need a lock
-	cache map[string]*cachedFieldDocs // Keyed by field
-	size  uint64
+	m         sync.Mutex                       // As the cache is asynchronously prepared, need a lock
+	cache     map[string]*cachedFieldDocs      // Keyed by field
+	summaries map[string]*cachedFieldSummaries // Keyed by field
+	points    map[string]*cachedFieldPoints    // Keyed by kind and field
+	size      uint64
 }
 
 func (c *cachedDocs) prepareFields(wantedFields []string, ss *SegmentSnapshot) error {
@@ -224,6 +388,48 @@ func (c *cachedDocs) prepareFields(wantedFields []string, ss *SegmentSnapshot) e
 	return nil
 }
 
+// prepareSummaries returns the summaries of the given fields, once
+// they're ready to be used
+func (c *cachedDocs) prepareSummaries(wantedFields []string,
+	ss *SegmentSnapshot) ([]*cachedFieldSummaries, error) {
+	rv := make([]*cachedFieldSummaries, len(wantedFields))
+
+	c.m.Lock()
+
+	if c.summaries == nil {
+		c.summaries = make(map[string]*cachedFieldSummaries, len(wantedFields))
+	}
+
+	for i, field := range wantedFields {
+		cfs, exists := c.summaries[field]
+		if !exists {
+			cfs = &cachedFieldSummaries{
+				readyCh: make(chan struct{}),
+			}
+			c.summaries[field] = cfs
+
+			go cfs.prepareField(field, ss)
+		}
+		rv[i] = cfs
+	}
+
+	c.m.Unlock()
+
+	for _, cfs := range rv {
+		<-cfs.readyCh
+
+		if cfs.err != nil {
+			return nil, cfs.err
+		}
+	}
+
+	c.m.Lock()
+	c.updateSizeLOCKED()
+	c.m.Unlock()
+
+	return rv, nil
+}
+
 // hasFields returns true if the cache has all the given fields
 func (c *cachedDocs) hasFields(fields []string) bool {
 	c.m.Lock()
@@ -249,6 +455,24 @@ func (c *cachedDocs) updateSizeLOCKED() {
 			sizeInBytes += v.Size()
 		}
 	}
+	for k, v := range c.summaries { // cachedFieldSummaries
+		sizeInBytes += len(k)
+		select {
+		case <-v.readyCh:
+			sizeInBytes += int(v.size)
+		default:
+		}
+	}
+	for k, v := range c.points { // cachedFieldPoints
+		sizeInBytes += len(k)
+		select {
+		case <-v.readyCh:
+			if v.tree != nil {
+				sizeInBytes += int(v.tree.size())
+			}
+		default:
+		}
+	}
 	atomic.StoreUint64(&c.size, uint64(sizeInBytes))
 }
 
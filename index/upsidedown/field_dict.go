@@ -30,12 +30,20 @@ type UpsideDownCouchFieldDict struct {
 }
 
 func newUpsideDownCouchFieldDict(indexReader *IndexReader, field uint16, startTerm, endTerm []byte) (*UpsideDownCouchFieldDict, error) {
+	if endTerm != nil {
+		endTerm = incrementBytes(endTerm)
+	}
+	return newUpsideDownCouchFieldDictExclusive(indexReader, field, startTerm, endTerm)
+}
+
+// newUpsideDownCouchFieldDictExclusive iterates the terms of the field
+// from startTerm, included, to endTerm, excluded, or to the last term
+// when endTerm is nil
+func newUpsideDownCouchFieldDictExclusive(indexReader *IndexReader, field uint16, startTerm, endTerm []byte) (*UpsideDownCouchFieldDict, error) {
 
 	startKey := NewDictionaryRow(startTerm, field, 0).Key()
 	if endTerm == nil {
 		endTerm = []byte{ByteSeparator}
-	} else {
-		endTerm = incrementBytes(endTerm)
 	}
 	endKey := NewDictionaryRow(endTerm, field, 0).Key()
 
@@ -76,3 +84,18 @@ func (r *UpsideDownCouchFieldDict) Next() (*index.DictEntry, error) {
 func (r *UpsideDownCouchFieldDict) Close() error {
 	return r.iterator.Close()
 }
+
+// filteredFieldDict skips the terms of a FieldDict not accepted by the
+// filter
+type filteredFieldDict struct {
+	index.FieldDict
+	filter func(term string) bool
+}
+
+func (r *filteredFieldDict) Next() (*index.DictEntry, error) {
+	entry, err := r.FieldDict.Next()
+	for err == nil && entry != nil && !r.filter(entry.Term) {
+		entry, err = r.FieldDict.Next()
+	}
+	return entry, err
+}
@@ -15,11 +15,14 @@
 package upsidedown
 
 import (
+	"fmt"
 	"reflect"
+	"regexp"
 
 	"github.com/blevesearch/bleve/document"
 	"github.com/blevesearch/bleve/index"
 	"github.com/blevesearch/bleve/index/store"
+	"github.com/blevesearch/bleve/search"
 )
 
 var reflectStaticSizeIndexReader int
@@ -59,6 +62,69 @@ func (i *IndexReader) FieldDictPrefix(fieldName string, termPrefix []byte) (inde
 	return i.FieldDictRange(fieldName, termPrefix, termPrefix)
 }
 
+func (i *IndexReader) FieldDictBounded(fieldName string, bounds index.FieldDictBounds) (index.FieldDict, error) {
+	fieldIndex, fieldExists := i.index.fieldCache.FieldNamed(fieldName, false)
+	if !fieldExists || bounds.Empty() {
+		return newUpsideDownCouchFieldDict(i, ^uint16(0), []byte{ByteSeparator}, []byte{})
+	}
+	startTerm, endTerm := bounds.KeyRange()
+	rv, err := newUpsideDownCouchFieldDictExclusive(i, uint16(fieldIndex), startTerm, endTerm)
+	if err != nil {
+		return nil, err
+	}
+	return index.NewLimitedFieldDict(rv, bounds.Limit), nil
+}
+
+// FieldDictRegexp enumerates the terms of the field entirely matched by
+// the regexp, scanning only the terms sharing its literal prefix
+func (i *IndexReader) FieldDictRegexp(fieldName string, termRegex string) (index.FieldDict, error) {
+	r, err := regexp.Compile(termRegex)
+	if err != nil {
+		return nil, err
+	}
+	prefix, _ := r.LiteralPrefix()
+	rv, err := i.fieldDictPrefixOrAll(fieldName, prefix)
+	if err != nil {
+		return nil, err
+	}
+	return &filteredFieldDict{
+		FieldDict: rv,
+		filter: func(term string) bool {
+			loc := r.FindStringIndex(term)
+			return loc != nil && loc[0] == 0 && loc[1] == len(term)
+		},
+	}, nil
+}
+
+// FieldDictFuzzy enumerates the terms of the field starting with the
+// prefix and within the fuzziness edit distance of the term
+func (i *IndexReader) FieldDictFuzzy(fieldName string, term string, fuzziness int, prefix string) (index.FieldDict, error) {
+	if fuzziness < 0 || fuzziness > 2 {
+		return nil, fmt.Errorf("fuzziness exceeds the max limit")
+	}
+	rv, err := i.fieldDictPrefixOrAll(fieldName, prefix)
+	if err != nil {
+		return nil, err
+	}
+	var reuse []int
+	return &filteredFieldDict{
+		FieldDict: rv,
+		filter: func(candidate string) bool {
+			var ld int
+			var exceeded bool
+			ld, exceeded, reuse = search.LevenshteinDistanceMaxReuseSlice(term, candidate, fuzziness, reuse)
+			return !exceeded && ld <= fuzziness
+		},
+	}, nil
+}
+
+func (i *IndexReader) fieldDictPrefixOrAll(fieldName string, prefix string) (index.FieldDict, error) {
+	if prefix == "" {
+		return i.FieldDict(fieldName)
+	}
+	return i.FieldDictPrefix(fieldName, []byte(prefix))
+}
+
 func (i *IndexReader) DocIDReaderAll() (index.DocIDReader, error) {
 	return newUpsideDownCouchDocIDReader(i)
 }
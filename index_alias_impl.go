@@ -256,6 +256,52 @@ func (i *indexAliasImpl) FieldDictPrefix(field string, termPrefix []byte) (index
 	}, nil
 }
 
+func (i *indexAliasImpl) FieldDictRegexp(field string, regex string) (index.FieldDict, error) {
+	return i.singleIndexFieldDict(func(idx Index) (index.FieldDict, error) {
+		return idx.FieldDictRegexp(field, regex)
+	})
+}
+
+func (i *indexAliasImpl) FieldDictFuzzy(field string, term string, fuzziness int, prefix string) (index.FieldDict, error) {
+	return i.singleIndexFieldDict(func(idx Index) (index.FieldDict, error) {
+		return idx.FieldDictFuzzy(field, term, fuzziness, prefix)
+	})
+}
+
+func (i *indexAliasImpl) FieldDictBounded(field string, bounds index.FieldDictBounds) (index.FieldDict, error) {
+	return i.singleIndexFieldDict(func(idx Index) (index.FieldDict, error) {
+		return idx.FieldDictBounded(field, bounds)
+	})
+}
+
+// singleIndexFieldDict returns the FieldDict built from the only index
+// of the alias
+func (i *indexAliasImpl) singleIndexFieldDict(build func(Index) (index.FieldDict, error)) (index.FieldDict, error) {
+	i.mutex.RLock()
+
+	if !i.open {
+		i.mutex.RUnlock()
+		return nil, ErrorIndexClosed
+	}
+
+	err := i.isAliasToSingleIndex()
+	if err != nil {
+		i.mutex.RUnlock()
+		return nil, err
+	}
+
+	fieldDict, err := build(i.indexes[0])
+	if err != nil {
+		i.mutex.RUnlock()
+		return nil, err
+	}
+
+	return &indexAliasImplFieldDict{
+		index:     i,
+		fieldDict: fieldDict,
+	}, nil
+}
+
 func (i *indexAliasImpl) Close() error {
 	i.mutex.Lock()
 	defer i.mutex.Unlock()
@@ -434,6 +480,8 @@ func createChildSearchRequest(req *SearchRequest) *SearchRequest {
 		Sort:             req.Sort.Copy(),
 		IncludeLocations: req.IncludeLocations,
 		Score:            req.Score,
+		SearcherWrappers: req.SearcherWrappers,
+		Collector:        req.Collector,
 	}
 	return &rv
 }
@@ -520,9 +568,7 @@ func MultiSearch(ctx context.Context, req *SearchRequest, indexes ...Index) (*Se
 	}
 
 	// fix up facets
-	for name, fr := range req.Facets {
-		sr.Facets.Fixup(name, fr.Size)
-	}
+	fixupFacets(sr.Facets, req.Facets)
 
 	// fix up original request
 	sr.Request = req
@@ -604,3 +650,16 @@ func (m *multiSearchHitSorter) Less(i, j int) bool {
 	c := m.sort.Compare(m.cachedScoring, m.cachedDesc, m.hits[i], m.hits[j])
 	return c < 0
 }
+
+// fixupFacets trims the merged facets to their requested size,
+// including the facets nested in the cells of geohash grid facets
+func fixupFacets(facets search.FacetResults, req FacetsRequest) {
+	for name, fr := range req {
+		facets.Fixup(name, fr.Size)
+		if facetResult, ok := facets[name]; ok && len(fr.Facets) > 0 {
+			for _, cell := range facetResult.GeohashCells {
+				fixupFacets(cell.Facets, fr.Facets)
+			}
+		}
+	}
+}
@@ -1308,6 +1308,18 @@ func (i *stubIndex) FieldDictPrefix(field string, termPrefix []byte) (index.Fiel
 	return nil, i.err
 }
 
+func (i *stubIndex) FieldDictRegexp(field string, regex string) (index.FieldDict, error) {
+	return nil, i.err
+}
+
+func (i *stubIndex) FieldDictFuzzy(field string, term string, fuzziness int, prefix string) (index.FieldDict, error) {
+	return nil, i.err
+}
+
+func (i *stubIndex) FieldDictBounded(field string, bounds index.FieldDictBounds) (index.FieldDict, error) {
+	return nil, i.err
+}
+
 func (i *stubIndex) Close() error {
 	return i.err
 }
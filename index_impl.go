@@ -33,6 +33,8 @@ import (
 	"github.com/blevesearch/bleve/search/collector"
 	"github.com/blevesearch/bleve/search/facet"
 	"github.com/blevesearch/bleve/search/highlight"
+	"github.com/blevesearch/bleve/search/query"
+	"github.com/blevesearch/bleve/search/searcher"
 )
 
 type indexImpl struct {
@@ -387,11 +389,29 @@ func init() {
 	documentEmptySize = d.Size()
 }
 
+// optimizeForTopScores lets the searcher skip the hits which cannot
+// make it into the results, when they are sorted by descending score
+func optimizeForTopScores(s search.Searcher, sort search.SortOrder) search.Searcher {
+	if len(sort) != 1 {
+		return s
+	}
+	if ss, ok := sort[0].(*search.SortScore); !ok || !ss.Desc {
+		return s
+	}
+	return searcher.OptimizeForTopScores(s)
+}
+
+// explainPlan describes how the searcher evaluates the query, a
+// helper as the searcher package is shadowed within SearchInContext
+func explainPlan(s search.Searcher) *search.Plan {
+	return searcher.ExplainPlan(s)
+}
+
 // memNeededForSearch is a helper function that returns an estimate of RAM
 // needed to execute a search request.
 func memNeededForSearch(req *SearchRequest,
 	searcher search.Searcher,
-	topnCollector *collector.TopNCollector) uint64 {
+	coll search.Collector) uint64 {
 
 	backingSize := req.Size + req.From + 1
 	if req.Size+req.From > collector.PreAllocSizeSkipCap {
@@ -402,7 +422,9 @@ func memNeededForSearch(req *SearchRequest,
 	estimate := 0
 
 	// overhead, size in bytes from collector
-	estimate += topnCollector.Size()
+	if sc, ok := coll.(interface{ Size() int }); ok {
+		estimate += sc.Size()
+	}
 
 	// pre-allocing DocumentMatchPool
 	estimate += searchContextEmptySize + numDocMatches*documentMatchEmptySize
@@ -442,7 +464,19 @@ func (i *indexImpl) SearchInContext(ctx context.Context, req *SearchRequest) (sr
 		return nil, ErrorIndexClosed
 	}
 
-	collector := collector.NewTopNCollector(req.Size, req.From, req.Sort)
+	var coll search.Collector
+	if req.Collector != nil {
+		newCollector, err := search.CollectorNamed(req.Collector.Name)
+		if err != nil {
+			return nil, err
+		}
+		coll, err = newCollector(req.Size, req.From, req.Sort, req.Collector.Config)
+		if err != nil {
+			return nil, err
+		}
+	} else {
+		coll = collector.NewTopNCollector(req.Size, req.From, req.Sort)
+	}
 
 	// open a reader for this search
 	indexReader, err := i.i.Reader()
@@ -469,35 +503,36 @@ func (i *indexImpl) SearchInContext(ctx context.Context, req *SearchRequest) (sr
 		}
 	}()
 
-	if req.Facets != nil {
-		facetsBuilder := search.NewFacetsBuilder(indexReader)
-		for facetName, facetRequest := range req.Facets {
-			if facetRequest.NumericRanges != nil {
-				// build numeric range facet
-				facetBuilder := facet.NewNumericFacetBuilder(facetRequest.Field, facetRequest.Size)
-				for _, nr := range facetRequest.NumericRanges {
-					facetBuilder.AddRange(nr.Name, nr.Min, nr.Max)
-				}
-				facetsBuilder.Add(facetName, facetBuilder)
-			} else if facetRequest.DateTimeRanges != nil {
-				// build date range facet
-				facetBuilder := facet.NewDateTimeFacetBuilder(facetRequest.Field, facetRequest.Size)
-				dateTimeParser := i.m.DateTimeParserNamed("")
-				for _, dr := range facetRequest.DateTimeRanges {
-					start, end := dr.ParseDates(dateTimeParser)
-					facetBuilder.AddRange(dr.Name, start, end)
-				}
-				facetsBuilder.Add(facetName, facetBuilder)
-			} else {
-				// build terms facet
-				facetBuilder := facet.NewTermsFacetBuilder(facetRequest.Field, facetRequest.Size)
-				facetsBuilder.Add(facetName, facetBuilder)
-			}
+	for _, sw := range req.SearcherWrappers {
+		wrap, err := search.SearcherWrapperNamed(sw.Name)
+		if err != nil {
+			return nil, err
+		}
+		wrapped, err := wrap(searcher, indexReader, sw.Config)
+		if err != nil {
+			return nil, err
 		}
-		collector.SetFacetsBuilder(facetsBuilder)
+		searcher = wrapped
 	}
 
-	memNeeded := memNeededForSearch(req, searcher, collector)
+	topnCollector, isTopN := coll.(*collector.TopNCollector)
+	if req.ApproximateTotal && req.Facets == nil && isTopN &&
+		len(req.SearcherWrappers) == 0 {
+		searcher = optimizeForTopScores(searcher, req.Sort)
+		topnCollector.SetApproximateTotal(true)
+	}
+
+	var plan *search.Plan
+	if req.ExplainPlan {
+		plan = explainPlan(searcher)
+	}
+
+	if req.Facets != nil {
+		facetsBuilder := i.newFacetsBuilder(indexReader, req.Facets)
+		coll.SetFacetsBuilder(facetsBuilder)
+	}
+
+	memNeeded := memNeededForSearch(req, searcher, coll)
 	if cb := ctx.Value(SearchQueryStartCallbackKey); cb != nil {
 		if cbF, ok := cb.(SearchQueryStartCallbackFn); ok {
 			err = cbF(memNeeded)
@@ -515,12 +550,12 @@ func (i *indexImpl) SearchInContext(ctx context.Context, req *SearchRequest) (sr
 		}
 	}
 
-	err = collector.Collect(ctx, searcher, indexReader)
+	err = coll.Collect(ctx, searcher, indexReader)
 	if err != nil {
 		return nil, err
 	}
 
-	hits := collector.Results()
+	hits := coll.Results()
 
 	var highlighter highlight.Highlighter
 
@@ -567,13 +602,55 @@ func (i *indexImpl) SearchInContext(ctx context.Context, req *SearchRequest) (sr
 		},
 		Request:  req,
 		Hits:     hits,
-		Total:    collector.Total(),
-		MaxScore: collector.MaxScore(),
+		Total:    coll.Total(),
+		MaxScore: coll.MaxScore(),
 		Took:     searchDuration,
-		Facets:   collector.FacetResults(),
+		Facets:   coll.FacetResults(),
+		Plan:     plan,
 	}, nil
 }
 
+// newFacetsBuilder builds the facets described by the FacetsRequest
+func (i *indexImpl) newFacetsBuilder(indexReader index.IndexReader,
+	facets FacetsRequest) *search.FacetsBuilder {
+	facetsBuilder := search.NewFacetsBuilder(indexReader)
+	for facetName, facetRequest := range facets {
+		if facetRequest.NumericRanges != nil {
+			// build numeric range facet
+			facetBuilder := facet.NewNumericFacetBuilder(facetRequest.Field, facetRequest.Size)
+			for _, nr := range facetRequest.NumericRanges {
+				facetBuilder.AddRange(nr.Name, nr.Min, nr.Max)
+			}
+			facetsBuilder.Add(facetName, facetBuilder)
+		} else if facetRequest.DateTimeRanges != nil {
+			// build date range facet
+			facetBuilder := facet.NewDateTimeFacetBuilder(facetRequest.Field, facetRequest.Size)
+			dateTimeParser := i.m.DateTimeParserNamed("")
+			for _, dr := range facetRequest.DateTimeRanges {
+				start, end := dr.ParseDates(dateTimeParser)
+				facetBuilder.AddRange(dr.Name, start, end)
+			}
+			facetsBuilder.Add(facetName, facetBuilder)
+		} else if facetRequest.GeohashPrecision > 0 {
+			// build geohash grid facet
+			facetBuilder := facet.NewGeohashGridFacetBuilder(facetRequest.Field,
+				facetRequest.Size, facetRequest.GeohashPrecision)
+			if len(facetRequest.Facets) > 0 {
+				nestedFacets := facetRequest.Facets
+				facetBuilder.SetNestedFacets(func() *search.FacetsBuilder {
+					return i.newFacetsBuilder(indexReader, nestedFacets)
+				})
+			}
+			facetsBuilder.Add(facetName, facetBuilder)
+		} else {
+			// build terms facet
+			facetBuilder := facet.NewTermsFacetBuilder(facetRequest.Field, facetRequest.Size)
+			facetsBuilder.Add(facetName, facetBuilder)
+		}
+	}
+	return facetsBuilder
+}
+
 func LoadAndHighlightFields(hit *search.DocumentMatch, req *SearchRequest,
 	indexName string, r index.IndexReader,
 	highlighter highlight.Highlighter) error {
@@ -584,7 +661,8 @@ func LoadAndHighlightFields(hit *search.DocumentMatch, req *SearchRequest,
 				fieldsToLoad := deDuplicate(req.Fields)
 				for _, f := range fieldsToLoad {
 					for _, docF := range doc.Fields {
-						if f == "*" || docF.Name() == f {
+						if f == "*" || docF.Name() == f ||
+							(query.IsFieldGlob(f) && query.MatchFieldGlob(f, docF.Name())) {
 							var value interface{}
 							switch docF := docF.(type) {
 							case *document.TextField:
@@ -751,6 +829,66 @@ func (i *indexImpl) FieldDictPrefix(field string, termPrefix []byte) (index.Fiel
 	}, nil
 }
 
+func (i *indexImpl) FieldDictRegexp(field string, regex string) (index.FieldDict, error) {
+	return i.readerFieldDict(func(indexReader index.IndexReader) (index.FieldDict, error) {
+		ir, ok := indexReader.(index.IndexReaderRegexp)
+		if !ok {
+			return nil, ErrorFieldDictUnsupported
+		}
+		return ir.FieldDictRegexp(field, regex)
+	})
+}
+
+func (i *indexImpl) FieldDictFuzzy(field string, term string, fuzziness int, prefix string) (index.FieldDict, error) {
+	return i.readerFieldDict(func(indexReader index.IndexReader) (index.FieldDict, error) {
+		ir, ok := indexReader.(index.IndexReaderFuzzy)
+		if !ok {
+			return nil, ErrorFieldDictUnsupported
+		}
+		return ir.FieldDictFuzzy(field, term, fuzziness, prefix)
+	})
+}
+
+func (i *indexImpl) FieldDictBounded(field string, bounds index.FieldDictBounds) (index.FieldDict, error) {
+	return i.readerFieldDict(func(indexReader index.IndexReader) (index.FieldDict, error) {
+		ir, ok := indexReader.(index.IndexReaderFieldDictBounded)
+		if !ok {
+			return nil, ErrorFieldDictUnsupported
+		}
+		return ir.FieldDictBounded(field, bounds)
+	})
+}
+
+// readerFieldDict returns the FieldDict built from a new IndexReader,
+// which is closed along with it
+func (i *indexImpl) readerFieldDict(build func(index.IndexReader) (index.FieldDict, error)) (index.FieldDict, error) {
+	i.mutex.RLock()
+
+	if !i.open {
+		i.mutex.RUnlock()
+		return nil, ErrorIndexClosed
+	}
+
+	indexReader, err := i.i.Reader()
+	if err != nil {
+		i.mutex.RUnlock()
+		return nil, err
+	}
+
+	fieldDict, err := build(indexReader)
+	if err != nil {
+		_ = indexReader.Close()
+		i.mutex.RUnlock()
+		return nil, err
+	}
+
+	return &indexImplFieldDict{
+		index:       i,
+		indexReader: indexReader,
+		fieldDict:   fieldDict,
+	}, nil
+}
+
 func (i *indexImpl) Close() error {
 	i.mutex.Lock()
 	defer i.mutex.Unlock()
@@ -611,6 +611,110 @@ func TestDict(t *testing.T) {
 	}
 }
 
+func TestIndexFieldDictBounded(t *testing.T) {
+	for _, indexType := range []string{upsidedown.Name, scorch.Name} {
+		t.Run(indexType, func(t *testing.T) {
+			defer func() {
+				err := os.RemoveAll("testidx")
+				if err != nil {
+					t.Fatal(err)
+				}
+			}()
+
+			idx, err := NewUsing("testidx", NewIndexMapping(), indexType,
+				Config.DefaultKVStore, nil)
+			if err != nil {
+				t.Fatal(err)
+			}
+			defer func() {
+				err := idx.Close()
+				if err != nil {
+					t.Fatal(err)
+				}
+			}()
+
+			err = idx.Index("a", map[string]interface{}{
+				"desc": "bob cat cats catting dog doggy zoo",
+			})
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			terms := func(dict index.FieldDict, err error) []string {
+				if err != nil {
+					t.Fatal(err)
+				}
+				rv := []string{}
+				de, err := dict.Next()
+				for err == nil && de != nil {
+					rv = append(rv, de.Term)
+					de, err = dict.Next()
+				}
+				if err != nil {
+					t.Fatal(err)
+				}
+				err = dict.Close()
+				if err != nil {
+					t.Fatal(err)
+				}
+				return rv
+			}
+
+			tests := []struct {
+				bounds   index.FieldDictBounds
+				expected []string
+			}{
+				{
+					bounds:   index.FieldDictBounds{Limit: 2},
+					expected: []string{"bob", "cat"},
+				},
+				{
+					bounds: index.FieldDictBounds{Start: []byte("cat"),
+						StartExclusive: true, Limit: 3},
+					expected: []string{"cats", "catting", "dog"},
+				},
+				{
+					bounds: index.FieldDictBounds{Start: []byte("cats"),
+						End: []byte("doggy"), EndExclusive: true},
+					expected: []string{"cats", "catting", "dog"},
+				},
+				{
+					bounds: index.FieldDictBounds{Prefix: []byte("cat"),
+						Start: []byte("cat"), StartExclusive: true},
+					expected: []string{"cats", "catting"},
+				},
+				{
+					bounds: index.FieldDictBounds{Prefix: []byte("dog"),
+						End: []byte("doggy"), EndExclusive: false},
+					expected: []string{"dog", "doggy"},
+				},
+				{
+					bounds: index.FieldDictBounds{Start: []byte("dog"),
+						End: []byte("dog"), EndExclusive: true},
+					expected: []string{},
+				},
+			}
+			for _, test := range tests {
+				actual := terms(idx.FieldDictBounded("desc", test.bounds))
+				if !reflect.DeepEqual(actual, test.expected) {
+					t.Errorf("%+v: expected %v, got %v", test.bounds,
+						test.expected, actual)
+				}
+			}
+
+			actual := terms(idx.FieldDictRegexp("desc", "ca.*s"))
+			if !reflect.DeepEqual(actual, []string{"cats"}) {
+				t.Errorf("expected regexp terms [cats], got %v", actual)
+			}
+
+			actual = terms(idx.FieldDictFuzzy("desc", "dogy", 1, ""))
+			if !reflect.DeepEqual(actual, []string{"dog", "doggy"}) {
+				t.Errorf("expected fuzzy terms [dog doggy], got %v", actual)
+			}
+		})
+	}
+}
+
 func TestBatchString(t *testing.T) {
 	defer func() {
 		err := os.RemoveAll("testidx")
@@ -861,6 +965,27 @@ func TestBatchReset(t *testing.T) {
 	if batch.Size() != 0 {
 		t.Errorf("expected batch size 0 after reset, got %d", batch.Size())
 	}
+	if batch.TotalDocsSize() != 0 || batch.LastDocSize() != 0 {
+		t.Errorf("expected doc sizes 0 after reset, got %d and %d",
+			batch.TotalDocsSize(), batch.LastDocSize())
+	}
+
+	// the reset batch can be reused
+	err = batch.Index("k5", map[string]interface{}{"Body": "v5"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = index.Batch(batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+	count, err := index.DocCount()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if count != 1 {
+		t.Errorf("expected 1 doc, got %d", count)
+	}
 
 	err = index.Close()
 	if err != nil {
@@ -2216,3 +2341,84 @@ func TestBatchRaceBug1149(t *testing.T) {
 	}
 	b.Reset()
 }
+
+func TestSearchIndexSortApproximateTotal(t *testing.T) {
+	defer func() {
+		err := os.RemoveAll("testidx")
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	idx, err := NewUsing("testidx", NewIndexMapping(), "scorch", "scorch",
+		map[string]interface{}{
+			"indexSort": "-n",
+		})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := idx.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	for b := 0; b < 5; b++ {
+		batch := idx.NewBatch()
+		for i := 0; i < 50; i++ {
+			doc := map[string]interface{}{
+				"desc": fmt.Sprintf("doc %d", i%3),
+			}
+			if i%7 != 0 {
+				doc["n"] = float64((b*50 + i) * 37 % 101)
+			}
+			err = batch.Index(fmt.Sprintf("%d-%d", b, i), doc)
+			if err != nil {
+				t.Fatal(err)
+			}
+		}
+		err = idx.Batch(batch)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, q := range []query.Query{NewMatchAllQuery(), NewMatchQuery("1")} {
+		for _, from := range []int{0, 3} {
+			for _, size := range []int{1, 10, 100} {
+				req := NewSearchRequestOptions(q, size, from, false)
+				req.SortBy([]string{"-n"})
+				expected, err := idx.Search(req)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				req.ApproximateTotal = true
+				actual, err := idx.Search(req)
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if len(actual.Hits) != len(expected.Hits) {
+					t.Fatalf("size: %d, from: %d, expected %d hits, got %d",
+						size, from, len(expected.Hits), len(actual.Hits))
+				}
+				for i := range expected.Hits {
+					if actual.Hits[i].ID != expected.Hits[i].ID {
+						t.Errorf("size: %d, from: %d, hit %d, expected %s, got %s",
+							size, from, i, expected.Hits[i].ID, actual.Hits[i].ID)
+					}
+				}
+				if actual.Total > expected.Total {
+					t.Errorf("size: %d, from: %d, total %d exceeds %d",
+						size, from, actual.Total, expected.Total)
+				}
+				if size == 1 && actual.Total == expected.Total {
+					t.Errorf("size: %d, from: %d, expected hits to be skipped",
+						size, from)
+				}
+			}
+		}
+	}
+}
@@ -117,11 +117,16 @@ func (dr *dateTimeRange) MarshalJSON() ([]byte, error) {
 // A FacetRequest describes a facet or aggregation
 // of the result document set you would like to be
 // built.
+// GeohashPrecision buckets the documents by the geohash
+// cells, of this many characters, of a geo point field,
+// and Facets are then built over the documents of each cell.
 type FacetRequest struct {
-	Size           int              `json:"size"`
-	Field          string           `json:"field"`
-	NumericRanges  []*numericRange  `json:"numeric_ranges,omitempty"`
-	DateTimeRanges []*dateTimeRange `json:"date_ranges,omitempty"`
+	Size             int              `json:"size"`
+	Field            string           `json:"field"`
+	NumericRanges    []*numericRange  `json:"numeric_ranges,omitempty"`
+	DateTimeRanges   []*dateTimeRange `json:"date_ranges,omitempty"`
+	GeohashPrecision int              `json:"geohash_precision,omitempty"`
+	Facets           FacetsRequest    `json:"facets,omitempty"`
 }
 
 func (fr *FacetRequest) Validate() error {
@@ -131,6 +136,20 @@ func (fr *FacetRequest) Validate() error {
 		return fmt.Errorf("facet can only conain numeric ranges or date ranges, not both")
 	}
 
+	if fr.GeohashPrecision != 0 {
+		if nrCount > 0 || drCount > 0 {
+			return fmt.Errorf("facet can only contain ranges or a geohash grid, not both")
+		}
+		if fr.GeohashPrecision < 1 || fr.GeohashPrecision > 12 {
+			return fmt.Errorf("geohash precision must be between 1 and 12, got %d", fr.GeohashPrecision)
+		}
+		return fr.Facets.Validate()
+	}
+
+	if len(fr.Facets) > 0 {
+		return fmt.Errorf("nested facets are only supported by geohash grid facets")
+	}
+
 	if nrCount > 0 {
 		nrNames := map[string]interface{}{}
 		for _, nr := range fr.NumericRanges {
@@ -173,6 +192,27 @@ func NewFacetRequest(field string, size int) *FacetRequest {
 	}
 }
 
+// NewGeohashGridFacetRequest creates a facet on the
+// specified geo point field, counting the documents in
+// each geohash cell of precision characters, and limiting
+// the number of cells to the specified size.
+func NewGeohashGridFacetRequest(field string, precision, size int) *FacetRequest {
+	return &FacetRequest{
+		Field:            field,
+		Size:             size,
+		GeohashPrecision: precision,
+	}
+}
+
+// AddFacet adds a facet built over the documents
+// of each cell of a geohash grid facet.
+func (fr *FacetRequest) AddFacet(facetName string, f *FacetRequest) {
+	if fr.Facets == nil {
+		fr.Facets = make(FacetsRequest, 1)
+	}
+	fr.Facets[facetName] = f
+}
+
 // AddDateTimeRange adds a bucket to a field
 // containing date values.  Documents with a
 // date value falling into this range are tabulated
@@ -262,19 +302,37 @@ func (h *HighlightRequest) AddField(field string) {
 // result score explanations.
 // Sort describes the desired order for the results to be returned.
 // Score controls the kind of scoring performed
+// ApproximateTotal allows skipping the hits which cannot
+// make it into the results, when sorting by descending score
+// or by the index sort of a scorch index, without facets, in
+// which case Total may undercount the matches.
+// ExplainPlan triggers inclusion of the plan chosen to
+// evaluate the query, with the estimated cost of its clauses.
 //
-// A special field named "*" can be used to return all fields.
+// A special field named "*" can be used to return all fields,
+// and glob patterns like "user.*" return the matching fields.
 type SearchRequest struct {
-	Query            query.Query       `json:"query"`
-	Size             int               `json:"size"`
-	From             int               `json:"from"`
-	Highlight        *HighlightRequest `json:"highlight"`
-	Fields           []string          `json:"fields"`
-	Facets           FacetsRequest     `json:"facets"`
-	Explain          bool              `json:"explain"`
-	Sort             search.SortOrder  `json:"sort"`
-	IncludeLocations bool              `json:"includeLocations"`
-	Score            string            `json:"score,omitempty"`
+	Query            query.Query        `json:"query"`
+	Size             int                `json:"size"`
+	From             int                `json:"from"`
+	Highlight        *HighlightRequest  `json:"highlight"`
+	Fields           []string           `json:"fields"`
+	Facets           FacetsRequest      `json:"facets"`
+	Explain          bool               `json:"explain"`
+	Sort             search.SortOrder   `json:"sort"`
+	IncludeLocations bool               `json:"includeLocations"`
+	Score            string             `json:"score,omitempty"`
+	ApproximateTotal bool               `json:"approximateTotal,omitempty"`
+	ExplainPlan      bool               `json:"explainPlan,omitempty"`
+	SearcherWrappers []*SearchExtension `json:"searcherWrappers,omitempty"`
+	Collector        *SearchExtension   `json:"collector,omitempty"`
+}
+
+// SearchExtension selects a searcher wrapper or a collector registered
+// in the search package, along with its configuration.
+type SearchExtension struct {
+	Name   string                 `json:"name"`
+	Config map[string]interface{} `json:"config,omitempty"`
 }
 
 func (r *SearchRequest) Validate() error {
@@ -285,6 +343,19 @@ func (r *SearchRequest) Validate() error {
 		}
 	}
 
+	for _, sw := range r.SearcherWrappers {
+		_, err := search.SearcherWrapperNamed(sw.Name)
+		if err != nil {
+			return err
+		}
+	}
+	if r.Collector != nil {
+		_, err := search.CollectorNamed(r.Collector.Name)
+		if err != nil {
+			return err
+		}
+	}
+
 	return r.Facets.Validate()
 }
 
@@ -315,16 +386,20 @@ func (r *SearchRequest) SortByCustom(order search.SortOrder) {
 // a SearchRequest
 func (r *SearchRequest) UnmarshalJSON(input []byte) error {
 	var temp struct {
-		Q                json.RawMessage   `json:"query"`
-		Size             *int              `json:"size"`
-		From             int               `json:"from"`
-		Highlight        *HighlightRequest `json:"highlight"`
-		Fields           []string          `json:"fields"`
-		Facets           FacetsRequest     `json:"facets"`
-		Explain          bool              `json:"explain"`
-		Sort             []json.RawMessage `json:"sort"`
-		IncludeLocations bool              `json:"includeLocations"`
-		Score            string            `json:"score"`
+		Q                json.RawMessage    `json:"query"`
+		Size             *int               `json:"size"`
+		From             int                `json:"from"`
+		Highlight        *HighlightRequest  `json:"highlight"`
+		Fields           []string           `json:"fields"`
+		Facets           FacetsRequest      `json:"facets"`
+		Explain          bool               `json:"explain"`
+		Sort             []json.RawMessage  `json:"sort"`
+		IncludeLocations bool               `json:"includeLocations"`
+		Score            string             `json:"score"`
+		ApproximateTotal bool               `json:"approximateTotal"`
+		ExplainPlan      bool               `json:"explainPlan"`
+		SearcherWrappers []*SearchExtension `json:"searcherWrappers"`
+		Collector        *SearchExtension   `json:"collector"`
 	}
 
 	err := json.Unmarshal(input, &temp)
@@ -352,6 +427,10 @@ func (r *SearchRequest) UnmarshalJSON(input []byte) error {
 	r.Facets = temp.Facets
 	r.IncludeLocations = temp.IncludeLocations
 	r.Score = temp.Score
+	r.ApproximateTotal = temp.ApproximateTotal
+	r.ExplainPlan = temp.ExplainPlan
+	r.SearcherWrappers = temp.SearcherWrappers
+	r.Collector = temp.Collector
 	r.Query, err = query.ParseQuery(temp.Q)
 	if err != nil {
 		return err
@@ -448,6 +527,7 @@ type SearchResult struct {
 	MaxScore float64                        `json:"max_score"`
 	Took     time.Duration                  `json:"took"`
 	Facets   search.FacetResults            `json:"facets"`
+	Plan     *search.Plan                   `json:"plan,omitempty"`
 }
 
 func (sr *SearchResult) Size() int {
@@ -31,6 +31,14 @@ type Collector interface {
 	FacetResults() FacetResults
 }
 
+// CompetitiveScoreCollector is an optional interface implemented by
+// collectors which can tell searchers the score a match must exceed to
+// make it into the results.  Searchers may skip any match whose score
+// is known to be less than or equal to this score.
+type CompetitiveScoreCollector interface {
+	MinCompetitiveScore() (score float64, ok bool)
+}
+
 // DocumentMatchHandler is the type of document match callback
 // bleve will invoke during the search.
 // Eventually, bleve will indicate the completion of an ongoing search,
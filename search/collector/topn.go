@@ -59,6 +59,8 @@ type TopNCollector struct {
 	results       search.DocumentMatchCollection
 	facetsBuilder *search.FacetsBuilder
 
+	approximateTotal bool
+
 	store collectorStore
 
 	needDocIds    bool
@@ -69,6 +71,7 @@ type TopNCollector struct {
 	lowestMatchOutsideResults *search.DocumentMatch
 	updateFieldVisitor        index.DocumentFieldTermVisitor
 	dvReader                  index.DocValueReader
+	dvSummaryReader           index.DocValueSummaryReader
 }
 
 // CheckDoneEvery controls how frequently we check the context deadline
@@ -127,6 +130,13 @@ func (hc *TopNCollector) Size() int {
 
 // Collect goes to the index to find the matching documents
 func (hc *TopNCollector) Collect(ctx context.Context, searcher search.Searcher, reader index.IndexReader) error {
+	// when no hits are requested, but facets are, there is no need
+	// to maintain the top-N store at all
+	if hc.size == 0 && hc.facetsBuilder != nil &&
+		ctx.Value(search.MakeDocumentMatchHandlerKey) == nil {
+		return hc.collectFacetsOnly(ctx, searcher, reader)
+	}
+
 	startTime := time.Now()
 	var err error
 	var next *search.DocumentMatch
@@ -144,16 +154,38 @@ func (hc *TopNCollector) Collect(ctx context.Context, searcher search.Searcher,
 		IndexReader:       reader,
 	}
 
-	hc.dvReader, err = reader.DocValueReader(hc.neededFields)
-	if err != nil {
-		return err
+	// when possible, the sort values come from the memoized summaries
+	// of the terms of the sort fields, rather than from visiting them all
+	dvFields := hc.neededFields
+	hc.dvSummaryReader = nil
+	sortFields := hc.sort.RequiredFields()
+	if dvsr, ok := reader.(index.IndexReaderDocValueSummary); ok &&
+		len(sortFields) > 0 && hc.sort.Summarizable() {
+		hc.dvSummaryReader, err = dvsr.DocValueSummaryReader(sortFields)
+		if err != nil {
+			return err
+		}
+		dvFields = nil
+		if hc.facetsBuilder != nil {
+			dvFields = hc.facetsBuilder.RequiredFields()
+		}
+	}
+
+	hc.dvReader = nil
+	if hc.dvSummaryReader == nil || len(dvFields) > 0 {
+		hc.dvReader, err = reader.DocValueReader(dvFields)
+		if err != nil {
+			return err
+		}
 	}
 
 	hc.updateFieldVisitor = func(field string, term []byte) {
 		if hc.facetsBuilder != nil {
 			hc.facetsBuilder.UpdateVisitor(field, term)
 		}
-		hc.sort.UpdateVisitor(field, term)
+		if hc.dvSummaryReader == nil {
+			hc.sort.UpdateVisitor(field, term)
+		}
 	}
 
 	dmHandlerMaker := MakeTopNDocumentMatchHandler
@@ -169,6 +201,13 @@ func (hc *TopNCollector) Collect(ctx context.Context, searcher search.Searcher,
 
 	hc.needDocIds = hc.needDocIds || loadID
 
+	// when the documents of each segment are ordered the way the hits
+	// are sorted, the first size+skip hits of a segment are its best
+	segmentSort := hc.segmentSortReader(ctx, reader)
+	var segmentEnd index.IndexInternalID
+	var segmentHits int
+	var inSegment bool
+
 	select {
 	case <-ctx.Done():
 		return ctx.Err()
@@ -184,6 +223,19 @@ func (hc *TopNCollector) Collect(ctx context.Context, searcher search.Searcher,
 			}
 		}
 
+		if segmentSort != nil {
+			if !inSegment ||
+				(segmentEnd != nil && next.IndexInternalID.Compare(segmentEnd) >= 0) {
+				segmentEnd, err = segmentSort.NextSegmentStart(next.IndexInternalID)
+				if err != nil {
+					break
+				}
+				inSegment = true
+				segmentHits = 0
+			}
+			segmentHits++
+		}
+
 		err = hc.prepareDocumentMatch(searchContext, reader, next)
 		if err != nil {
 			break
@@ -194,6 +246,15 @@ func (hc *TopNCollector) Collect(ctx context.Context, searcher search.Searcher,
 			break
 		}
 
+		if segmentSort != nil && segmentHits >= hc.size+hc.skip {
+			if segmentEnd == nil {
+				break
+			}
+			inSegment = false
+			next, err = searcher.Advance(searchContext, segmentEnd)
+			continue
+		}
+
 		next, err = searcher.Next(searchContext)
 	}
 
@@ -217,6 +278,68 @@ func (hc *TopNCollector) Collect(ctx context.Context, searcher search.Searcher,
 	return nil
 }
 
+// collectFacetsOnly drives the facets builder straight from the
+// searcher, using the doc values of the facet fields only.  Hits are
+// returned to the pool immediately, skipping the sort value computation,
+// doc id lookup and store maintenance of regular top-N collection.
+func (hc *TopNCollector) collectFacetsOnly(ctx context.Context,
+	searcher search.Searcher, reader index.IndexReader) error {
+	startTime := time.Now()
+	var err error
+	var next *search.DocumentMatch
+
+	searchContext := &search.SearchContext{
+		DocumentMatchPool: search.NewDocumentMatchPool(searcher.DocumentMatchPoolSize()+1, 0),
+		Collector:         hc,
+		IndexReader:       reader,
+	}
+
+	hc.dvReader, err = reader.DocValueReader(hc.facetsBuilder.RequiredFields())
+	if err != nil {
+		return err
+	}
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	default:
+		next, err = searcher.Next(searchContext)
+	}
+	for err == nil && next != nil {
+		if hc.total%CheckDoneEvery == 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			default:
+			}
+		}
+
+		hc.total++
+		if next.Score > hc.maxScore {
+			hc.maxScore = next.Score
+		}
+
+		hc.facetsBuilder.StartDoc()
+		err = hc.dvReader.VisitDocValues(next.IndexInternalID,
+			hc.facetsBuilder.UpdateVisitor)
+		hc.facetsBuilder.EndDoc()
+		if err != nil {
+			break
+		}
+
+		searchContext.DocumentMatchPool.Put(next)
+		next, err = searcher.Next(searchContext)
+	}
+
+	hc.took = time.Since(startTime)
+	if err != nil {
+		return err
+	}
+
+	hc.results = search.DocumentMatchCollection{}
+	return nil
+}
+
 var sortByScoreOpt = []string{"_score"}
 
 func (hc *TopNCollector) prepareDocumentMatch(ctx *search.SearchContext,
@@ -299,6 +422,17 @@ func MakeTopNDocumentMatchHandler(
 	return nil, false, nil
 }
 
+// MinCompetitiveScore implements the search.CompetitiveScoreCollector
+// interface, it only reports a score once the collector is sorting by
+// descending score and has seen more hits than it keeps
+func (hc *TopNCollector) MinCompetitiveScore() (float64, bool) {
+	if len(hc.sort) != 1 || !hc.cachedScoring[0] || !hc.cachedDesc[0] ||
+		hc.lowestMatchOutsideResults == nil {
+		return 0, false
+	}
+	return hc.lowestMatchOutsideResults.Score, true
+}
+
 // visitFieldTerms is responsible for visiting the field terms of the
 // search hit, and passing visited terms to the sort and facet builder
 func (hc *TopNCollector) visitFieldTerms(reader index.IndexReader, d *search.DocumentMatch) error {
@@ -306,7 +440,14 @@ func (hc *TopNCollector) visitFieldTerms(reader index.IndexReader, d *search.Doc
 		hc.facetsBuilder.StartDoc()
 	}
 
-	err := hc.dvReader.VisitDocValues(d.IndexInternalID, hc.updateFieldVisitor)
+	var err error
+	if hc.dvSummaryReader != nil {
+		err = hc.dvSummaryReader.VisitDocValueSummaries(d.IndexInternalID,
+			hc.sort.UpdateSummary)
+	}
+	if err == nil && hc.dvReader != nil {
+		err = hc.dvReader.VisitDocValues(d.IndexInternalID, hc.updateFieldVisitor)
+	}
 	if hc.facetsBuilder != nil {
 		hc.facetsBuilder.EndDoc()
 	}
@@ -314,6 +455,36 @@ func (hc *TopNCollector) visitFieldTerms(reader index.IndexReader, d *search.Doc
 	return err
 }
 
+// SetApproximateTotal allows the collector to skip hits which cannot
+// make it into the results, leaving the total an approximation
+func (hc *TopNCollector) SetApproximateTotal(approximateTotal bool) {
+	hc.approximateTotal = approximateTotal
+}
+
+// segmentSortReader returns the reader when the collector may stop
+// collecting the hits of a segment early, as the documents of its
+// segments are ordered exactly like the hits, or nil otherwise
+func (hc *TopNCollector) segmentSortReader(ctx context.Context,
+	reader index.IndexReader) index.IndexReaderSegmentSort {
+	if !hc.approximateTotal || hc.facetsBuilder != nil ||
+		hc.size+hc.skip <= 0 || len(hc.sort) != 1 ||
+		ctx.Value(search.MakeDocumentMatchHandlerKey) != nil {
+		return nil
+	}
+	r, ok := reader.(index.IndexReaderSegmentSort)
+	if !ok {
+		return nil
+	}
+	field, desc := r.SegmentSort()
+	sf, ok := hc.sort[0].(*search.SortField)
+	if !ok || field == "" || sf.Field != field || sf.Desc != desc ||
+		sf.Type != search.SortFieldAuto || sf.Mode != search.SortFieldDefault ||
+		sf.Missing != search.SortFieldMissingLast {
+		return nil
+	}
+	return r
+}
+
 // SetFacetsBuilder registers a facet builder for this collector
 func (hc *TopNCollector) SetFacetsBuilder(facetsBuilder *search.FacetsBuilder) {
 	hc.facetsBuilder = facetsBuilder
@@ -0,0 +1,84 @@
+//  Copyright (c) 2019 Couchbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 		http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"fmt"
+	"sync"
+
+	"github.com/blevesearch/bleve/index"
+)
+
+// SearcherWrapperConstructor wraps the searcher of a query with one
+// altering its matches, e.g. to deduplicate or rescore them.  Closing
+// the returned searcher must close the wrapped one.
+type SearcherWrapperConstructor func(searcher Searcher,
+	reader index.IndexReader, config map[string]interface{}) (Searcher, error)
+
+// CollectorConstructor builds a collector returning size matches, after
+// skipping the first from, in the sort order.
+type CollectorConstructor func(size, from int, sort SortOrder,
+	config map[string]interface{}) (Collector, error)
+
+var extensionsMutex sync.RWMutex
+var searcherWrappers = map[string]SearcherWrapperConstructor{}
+var collectors = map[string]CollectorConstructor{}
+
+// RegisterSearcherWrapper registers a searcher wrapper, which search
+// requests can then select by name.
+func RegisterSearcherWrapper(name string, constructor SearcherWrapperConstructor) {
+	extensionsMutex.Lock()
+	defer extensionsMutex.Unlock()
+	_, exists := searcherWrappers[name]
+	if exists {
+		panic(fmt.Errorf("attempted to register duplicate searcher wrapper named '%s'", name))
+	}
+	searcherWrappers[name] = constructor
+}
+
+// SearcherWrapperNamed returns the searcher wrapper registered with the
+// name.
+func SearcherWrapperNamed(name string) (SearcherWrapperConstructor, error) {
+	extensionsMutex.RLock()
+	defer extensionsMutex.RUnlock()
+	rv, exists := searcherWrappers[name]
+	if !exists {
+		return nil, fmt.Errorf("no searcher wrapper named '%s' registered", name)
+	}
+	return rv, nil
+}
+
+// RegisterCollector registers a collector, which search requests can
+// then select by name instead of the default top N collector.
+func RegisterCollector(name string, constructor CollectorConstructor) {
+	extensionsMutex.Lock()
+	defer extensionsMutex.Unlock()
+	_, exists := collectors[name]
+	if exists {
+		panic(fmt.Errorf("attempted to register duplicate collector named '%s'", name))
+	}
+	collectors[name] = constructor
+}
+
+// CollectorNamed returns the collector registered with the name.
+func CollectorNamed(name string) (CollectorConstructor, error) {
+	extensionsMutex.RLock()
+	defer extensionsMutex.RUnlock()
+	rv, exists := collectors[name]
+	if !exists {
+		return nil, fmt.Errorf("no collector named '%s' registered", name)
+	}
+	return rv, nil
+}
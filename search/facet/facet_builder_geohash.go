@@ -0,0 +1,211 @@
+//  Copyright (c) 2019 Couchbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 		http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package facet
+
+import (
+	"reflect"
+	"sort"
+
+	"github.com/blevesearch/bleve/geo"
+	"github.com/blevesearch/bleve/numeric"
+	"github.com/blevesearch/bleve/search"
+	"github.com/blevesearch/bleve/size"
+)
+
+var reflectStaticSizeGeohashGridFacetBuilder int
+var reflectStaticSizegeohashCell int
+
+func init() {
+	var gfb GeohashGridFacetBuilder
+	reflectStaticSizeGeohashGridFacetBuilder = int(reflect.TypeOf(gfb).Size())
+	var gc geohashCell
+	reflectStaticSizegeohashCell = int(reflect.TypeOf(gc).Size())
+}
+
+type geohashCell struct {
+	count  int
+	facets *search.FacetsBuilder
+}
+
+type fieldTerm struct {
+	field string
+	term  []byte
+}
+
+// GeohashGridFacetBuilder counts the documents with a geo point in
+// each geohash cell of the given precision, optionally building
+// nested facets over the documents of each cell
+type GeohashGridFacetBuilder struct {
+	size      int
+	field     string
+	precision int
+	cells     map[string]*geohashCell
+	total     int
+	missing   int
+
+	newNestedFacets func() *search.FacetsBuilder
+	nestedFields    []string
+
+	// the cells and nested field terms of the current document
+	docCells []string
+	docTerms []fieldTerm
+}
+
+func NewGeohashGridFacetBuilder(field string, size, precision int) *GeohashGridFacetBuilder {
+	return &GeohashGridFacetBuilder{
+		size:      size,
+		field:     field,
+		precision: precision,
+		cells:     make(map[string]*geohashCell),
+	}
+}
+
+// SetNestedFacets makes each cell build the facets returned by the
+// given func over its documents
+func (fb *GeohashGridFacetBuilder) SetNestedFacets(newNestedFacets func() *search.FacetsBuilder) {
+	fb.newNestedFacets = newNestedFacets
+	fb.nestedFields = newNestedFacets().RequiredFields()
+}
+
+func (fb *GeohashGridFacetBuilder) Size() int {
+	sizeInBytes := reflectStaticSizeGeohashGridFacetBuilder + size.SizeOfPtr +
+		len(fb.field)
+
+	for k, v := range fb.cells {
+		sizeInBytes += size.SizeOfString + len(k) +
+			size.SizeOfPtr + reflectStaticSizegeohashCell
+		if v.facets != nil {
+			sizeInBytes += v.facets.Size()
+		}
+	}
+
+	for _, entry := range fb.nestedFields {
+		sizeInBytes += size.SizeOfString + len(entry)
+	}
+
+	return sizeInBytes
+}
+
+func (fb *GeohashGridFacetBuilder) Field() string {
+	return fb.field
+}
+
+func (fb *GeohashGridFacetBuilder) NestedFields() []string {
+	return fb.nestedFields
+}
+
+func (fb *GeohashGridFacetBuilder) UpdateVisitor(field string, term []byte) {
+	if field == fb.field {
+		// only the full precision terms are points
+		valid, shift := numeric.ValidPrefixCodedTermBytes(term)
+		if valid && shift == 0 {
+			i64, err := numeric.PrefixCoded(term).Int64()
+			if err == nil {
+				lon := geo.MortonUnhashLon(uint64(i64))
+				lat := geo.MortonUnhashLat(uint64(i64))
+				fb.addDocCell(geo.GeoHashEncode(lat, lon, fb.precision))
+			}
+		}
+	}
+	for _, nestedField := range fb.nestedFields {
+		if field == nestedField {
+			fb.docTerms = append(fb.docTerms, fieldTerm{
+				field: field,
+				term:  append([]byte(nil), term...),
+			})
+			break
+		}
+	}
+}
+
+func (fb *GeohashGridFacetBuilder) addDocCell(cell string) {
+	for _, docCell := range fb.docCells {
+		if docCell == cell {
+			return
+		}
+	}
+	fb.docCells = append(fb.docCells, cell)
+}
+
+func (fb *GeohashGridFacetBuilder) StartDoc() {
+	fb.docCells = fb.docCells[:0]
+	fb.docTerms = fb.docTerms[:0]
+}
+
+func (fb *GeohashGridFacetBuilder) EndDoc() {
+	if len(fb.docCells) == 0 {
+		fb.missing++
+		return
+	}
+	for _, docCell := range fb.docCells {
+		cell, ok := fb.cells[docCell]
+		if !ok {
+			cell = &geohashCell{}
+			if fb.newNestedFacets != nil {
+				cell.facets = fb.newNestedFacets()
+			}
+			fb.cells[docCell] = cell
+		}
+		cell.count++
+		fb.total++
+
+		if cell.facets != nil {
+			cell.facets.StartDoc()
+			for _, ft := range fb.docTerms {
+				cell.facets.UpdateVisitor(ft.field, ft.term)
+			}
+			cell.facets.EndDoc()
+		}
+	}
+}
+
+func (fb *GeohashGridFacetBuilder) Result() *search.FacetResult {
+	rv := search.FacetResult{
+		Field:   fb.field,
+		Total:   fb.total,
+		Missing: fb.missing,
+	}
+
+	rv.GeohashCells = make([]*search.GeohashCellFacet, 0, len(fb.cells))
+
+	for geohash, cell := range fb.cells {
+		gcf := &search.GeohashCellFacet{
+			Geohash: geohash,
+			Count:   cell.count,
+		}
+
+		rv.GeohashCells = append(rv.GeohashCells, gcf)
+	}
+
+	sort.Sort(rv.GeohashCells)
+
+	// we now have the list of the top N cells
+	trimTopN := fb.size
+	if trimTopN > len(rv.GeohashCells) {
+		trimTopN = len(rv.GeohashCells)
+	}
+	rv.GeohashCells = rv.GeohashCells[:trimTopN]
+
+	notOther := 0
+	for _, gcf := range rv.GeohashCells {
+		notOther += gcf.Count
+		if facets := fb.cells[gcf.Geohash].facets; facets != nil {
+			gcf.Facets = facets.Results()
+		}
+	}
+	rv.Other = fb.total - notOther
+
+	return &rv
+}
@@ -0,0 +1,88 @@
+//  Copyright (c) 2019 Couchbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 		http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package facet
+
+import (
+	"testing"
+
+	"github.com/blevesearch/bleve/geo"
+	"github.com/blevesearch/bleve/numeric"
+	"github.com/blevesearch/bleve/search"
+)
+
+func TestGeohashGridFacetBuilder(t *testing.T) {
+	geoTerms := func(lon, lat float64) [][]byte {
+		hash := int64(geo.MortonHash(lon, lat))
+		return [][]byte{
+			numeric.MustNewPrefixCodedInt64(hash, 0),
+			// lower precision terms are ignored
+			numeric.MustNewPrefixCodedInt64(hash, 16),
+		}
+	}
+
+	docs := []struct {
+		points [][2]float64
+		tag    string
+	}{
+		{points: [][2]float64{{2.3522, 48.8566}}, tag: "city"},             // paris, u09
+		{points: [][2]float64{{2.2945, 48.8584}}, tag: "tower"},            // paris, u09
+		{points: [][2]float64{{-0.1276, 51.5072}}, tag: "city"},            // london, gcp
+		{points: [][2]float64{{2.3522, 48.8566}, {2.35, 48.85}}, tag: "x"}, // twice in u09
+		{tag: "nowhere"},
+	}
+
+	fb := NewGeohashGridFacetBuilder("loc", 1, 3)
+	fb.SetNestedFacets(func() *search.FacetsBuilder {
+		nested := search.NewFacetsBuilder(nil)
+		nested.Add("tags", NewTermsFacetBuilder("tag", 10))
+		return nested
+	})
+	if fields := fb.NestedFields(); len(fields) != 1 || fields[0] != "tag" {
+		t.Fatalf("expected nested fields [tag], got %v", fields)
+	}
+
+	for _, doc := range docs {
+		fb.StartDoc()
+		for _, point := range doc.points {
+			for _, term := range geoTerms(point[0], point[1]) {
+				fb.UpdateVisitor("loc", term)
+			}
+		}
+		fb.UpdateVisitor("tag", []byte(doc.tag))
+		fb.EndDoc()
+	}
+
+	result := fb.Result()
+	if result.Total != 4 || result.Missing != 1 || result.Other != 1 {
+		t.Errorf("expected total 4, missing 1, other 1, got %d, %d, %d",
+			result.Total, result.Missing, result.Other)
+	}
+	if len(result.GeohashCells) != 1 {
+		t.Fatalf("expected 1 cell, got %d", len(result.GeohashCells))
+	}
+	cell := result.GeohashCells[0]
+	if cell.Geohash != "u09" || cell.Count != 3 {
+		t.Errorf("expected 3 docs in u09, got %d in %s", cell.Count, cell.Geohash)
+	}
+	tags := cell.Facets["tags"]
+	if tags == nil || tags.Total != 3 || len(tags.Terms) != 3 {
+		t.Fatalf("expected 3 tags in u09, got %+v", tags)
+	}
+	for _, tf := range tags.Terms {
+		if tf.Term != "city" && tf.Term != "tower" && tf.Term != "x" {
+			t.Errorf("unexpected tag %s in u09", tf.Term)
+		}
+	}
+}
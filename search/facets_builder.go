@@ -27,6 +27,7 @@ var reflectStaticSizeFacetResult int
 var reflectStaticSizeTermFacet int
 var reflectStaticSizeNumericRangeFacet int
 var reflectStaticSizeDateRangeFacet int
+var reflectStaticSizeGeohashCellFacet int
 
 func init() {
 	var fb FacetsBuilder
@@ -39,6 +40,8 @@ func init() {
 	reflectStaticSizeNumericRangeFacet = int(reflect.TypeOf(nrf).Size())
 	var drf DateRangeFacet
 	reflectStaticSizeDateRangeFacet = int(reflect.TypeOf(drf).Size())
+	var gcf GeohashCellFacet
+	reflectStaticSizeGeohashCellFacet = int(reflect.TypeOf(gcf).Size())
 }
 
 type FacetBuilder interface {
@@ -52,6 +55,12 @@ type FacetBuilder interface {
 	Size() int
 }
 
+// NestedFacetBuilder is implemented by the FacetBuilders which also
+// build facets from the terms of other fields than their own
+type NestedFacetBuilder interface {
+	NestedFields() []string
+}
+
 type FacetsBuilder struct {
 	indexReader index.IndexReader
 	facetNames  []string
@@ -82,7 +91,23 @@ func (fb *FacetsBuilder) Size() int {
 func (fb *FacetsBuilder) Add(name string, facetBuilder FacetBuilder) {
 	fb.facetNames = append(fb.facetNames, name)
 	fb.facets = append(fb.facets, facetBuilder)
-	fb.fields = append(fb.fields, facetBuilder.Field())
+	fb.addField(facetBuilder.Field())
+	if nfb, ok := facetBuilder.(NestedFacetBuilder); ok {
+		for _, field := range nfb.NestedFields() {
+			fb.addField(field)
+		}
+	}
+}
+
+// addField adds a required field, once, as the terms of duplicate
+// fields would get visited more than once
+func (fb *FacetsBuilder) addField(field string) {
+	for _, f := range fb.fields {
+		if f == field {
+			return
+		}
+	}
+	fb.fields = append(fb.fields, field)
 }
 
 func (fb *FacetsBuilder) RequiredFields() []string {
@@ -241,6 +266,38 @@ func (drf DateRangeFacets) Less(i, j int) bool {
 	return drf[i].Count > drf[j].Count
 }
 
+type GeohashCellFacet struct {
+	Geohash string       `json:"geohash"`
+	Count   int          `json:"count"`
+	Facets  FacetResults `json:"facets,omitempty"`
+}
+
+type GeohashCellFacets []*GeohashCellFacet
+
+func (gcf GeohashCellFacets) Add(geohashCellFacet *GeohashCellFacet) GeohashCellFacets {
+	for _, existingCell := range gcf {
+		if geohashCellFacet.Geohash == existingCell.Geohash {
+			existingCell.Count += geohashCellFacet.Count
+			if existingCell.Facets != nil && geohashCellFacet.Facets != nil {
+				existingCell.Facets.Merge(geohashCellFacet.Facets)
+			}
+			return gcf
+		}
+	}
+	// if we got here it wasn't already in the existing cells
+	gcf = append(gcf, geohashCellFacet)
+	return gcf
+}
+
+func (gcf GeohashCellFacets) Len() int      { return len(gcf) }
+func (gcf GeohashCellFacets) Swap(i, j int) { gcf[i], gcf[j] = gcf[j], gcf[i] }
+func (gcf GeohashCellFacets) Less(i, j int) bool {
+	if gcf[i].Count == gcf[j].Count {
+		return gcf[i].Geohash < gcf[j].Geohash
+	}
+	return gcf[i].Count > gcf[j].Count
+}
+
 type FacetResult struct {
 	Field         string             `json:"field"`
 	Total         int                `json:"total"`
@@ -249,14 +306,24 @@ type FacetResult struct {
 	Terms         TermFacets         `json:"terms,omitempty"`
 	NumericRanges NumericRangeFacets `json:"numeric_ranges,omitempty"`
 	DateRanges    DateRangeFacets    `json:"date_ranges,omitempty"`
+	GeohashCells  GeohashCellFacets  `json:"geohash_cells,omitempty"`
 }
 
 func (fr *FacetResult) Size() int {
-	return reflectStaticSizeFacetResult + size.SizeOfPtr +
+	sizeInBytes := reflectStaticSizeFacetResult + size.SizeOfPtr +
 		len(fr.Field) +
 		len(fr.Terms)*(reflectStaticSizeTermFacet+size.SizeOfPtr) +
 		len(fr.NumericRanges)*(reflectStaticSizeNumericRangeFacet+size.SizeOfPtr) +
-		len(fr.DateRanges)*(reflectStaticSizeDateRangeFacet+size.SizeOfPtr)
+		len(fr.DateRanges)*(reflectStaticSizeDateRangeFacet+size.SizeOfPtr) +
+		len(fr.GeohashCells)*(reflectStaticSizeGeohashCellFacet+size.SizeOfPtr)
+
+	for _, cell := range fr.GeohashCells {
+		for k, v := range cell.Facets {
+			sizeInBytes += size.SizeOfString + len(k) + v.Size()
+		}
+	}
+
+	return sizeInBytes
 }
 
 func (fr *FacetResult) Merge(other *FacetResult) {
@@ -278,6 +345,11 @@ func (fr *FacetResult) Merge(other *FacetResult) {
 			fr.DateRanges = fr.DateRanges.Add(dr)
 		}
 	}
+	if fr.GeohashCells != nil && other.GeohashCells != nil {
+		for _, cell := range other.GeohashCells {
+			fr.GeohashCells = fr.GeohashCells.Add(cell)
+		}
+	}
 }
 
 func (fr *FacetResult) Fixup(size int) {
@@ -308,6 +380,15 @@ func (fr *FacetResult) Fixup(size int) {
 			}
 			fr.DateRanges = fr.DateRanges[0:size]
 		}
+	} else if fr.GeohashCells != nil {
+		sort.Sort(fr.GeohashCells)
+		if len(fr.GeohashCells) > size {
+			moveToOther := fr.GeohashCells[size:]
+			for _, mto := range moveToOther {
+				fr.Other += mto.Count
+			}
+			fr.GeohashCells = fr.GeohashCells[0:size]
+		}
 	}
 }
 
@@ -0,0 +1,41 @@
+//  Copyright (c) 2019 Couchbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 		http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package search
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Plan describes how a searcher evaluates its part of a query.  Cost
+// is the estimated number of matching documents, derived from term
+// statistics, which drives the order of conjunction clauses and the
+// strategies chosen.
+type Plan struct {
+	Searcher string  `json:"searcher"`
+	Strategy string  `json:"strategy,omitempty"`
+	Field    string  `json:"field,omitempty"`
+	Term     string  `json:"term,omitempty"`
+	Cost     uint64  `json:"cost"`
+	Children []*Plan `json:"children,omitempty"`
+}
+
+func (p *Plan) String() string {
+	js, err := json.MarshalIndent(p, "", "  ")
+	if err != nil {
+		return fmt.Sprintf("error serializing plan to json: %v", err)
+	}
+	return string(js)
+}
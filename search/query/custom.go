@@ -0,0 +1,58 @@
+//  Copyright (c) 2019 Couchbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 		http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"fmt"
+	"sync"
+)
+
+// QueryUnmarshaler deserializes the JSON representation of a custom
+// query type.  Queries nested within it can be deserialized with
+// ParseQuery.
+type QueryUnmarshaler func(input []byte) (Query, error)
+
+var customQueryTypesMutex sync.RWMutex
+var customQueryTypes = map[string]QueryUnmarshaler{}
+
+// RegisterQueryType registers a custom query type, which ParseQuery
+// recognizes in JSON objects having the name as a key, at the top level
+// or nested within compound queries.  Custom query types are checked
+// before the builtin ones, so the name should not be a key used by the
+// builtin queries, such as "field" or "boost".
+func RegisterQueryType(name string, unmarshal QueryUnmarshaler) {
+	customQueryTypesMutex.Lock()
+	defer customQueryTypesMutex.Unlock()
+	_, exists := customQueryTypes[name]
+	if exists {
+		panic(fmt.Errorf("attempted to register duplicate query type named '%s'", name))
+	}
+	customQueryTypes[name] = unmarshal
+}
+
+// parseCustomQuery returns the query deserialized by the custom query
+// type registered for one of the keys, if any
+func parseCustomQuery(input []byte, keys map[string]interface{}) (
+	Query, bool, error) {
+	customQueryTypesMutex.RLock()
+	defer customQueryTypesMutex.RUnlock()
+	for name, unmarshal := range customQueryTypes {
+		if _, ok := keys[name]; ok {
+			rv, err := unmarshal(input)
+			return rv, true, err
+		}
+	}
+	return nil, false, nil
+}
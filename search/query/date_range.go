@@ -135,6 +135,14 @@ func (q *DateRangeQuery) Searcher(i index.IndexReader, m mapping.IndexMapping, o
 	if q.FieldVal == "" {
 		field = m.DefaultSearchField()
 	}
+	if IsFieldGlob(field) {
+		return fieldGlobSearcher(i, field, options,
+			func(field string) (search.Searcher, error) {
+				qc := *q
+				qc.FieldVal = field
+				return qc.Searcher(i, m, options)
+			})
+	}
 
 	return searcher.NewNumericRangeSearcher(i, min, max, q.InclusiveStart, q.InclusiveEnd, field, q.BoostVal.Value(), options)
 }
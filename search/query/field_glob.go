@@ -0,0 +1,77 @@
+//  Copyright (c) 2019 Couchbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 		http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package query
+
+import (
+	"path"
+	"strings"
+
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/search"
+	"github.com/blevesearch/bleve/search/searcher"
+)
+
+// IsFieldGlob returns true if the field name is a glob pattern, like
+// "user.*", to be expanded against the fields of the index
+func IsFieldGlob(field string) bool {
+	return strings.Contains(field, "*")
+}
+
+// MatchFieldGlob returns true if the field matches the glob pattern.
+// The pattern syntax is the one of path.Match, with "*" also matching
+// any "." of the field name.  Fields starting with "_", like _id and
+// _all, only match patterns starting with "_".
+func MatchFieldGlob(glob, field string) bool {
+	if strings.HasPrefix(field, "_") && !strings.HasPrefix(glob, "_") {
+		return false
+	}
+	matched, err := path.Match(glob, field)
+	return err == nil && matched
+}
+
+// fieldGlobSearcher builds a searcher for each field of the index
+// matching the glob pattern, and combines them in a disjunction
+func fieldGlobSearcher(i index.IndexReader, glob string,
+	options search.SearcherOptions,
+	fieldSearcher func(field string) (search.Searcher, error)) (
+	search.Searcher, error) {
+	fields, err := i.Fields()
+	if err != nil {
+		return nil, err
+	}
+
+	var ss []search.Searcher
+	for _, field := range fields {
+		if !MatchFieldGlob(glob, field) {
+			continue
+		}
+		s, err := fieldSearcher(field)
+		if err != nil {
+			for _, s := range ss {
+				_ = s.Close()
+			}
+			return nil, err
+		}
+		ss = append(ss, s)
+	}
+
+	if len(ss) < 1 {
+		return searcher.NewMatchNoneSearcher(i)
+	} else if len(ss) == 1 {
+		return ss[0], nil
+	}
+
+	return searcher.NewDisjunctionSearcher(i, ss, 0, options)
+}
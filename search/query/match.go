@@ -120,6 +120,14 @@ func (q *MatchQuery) Searcher(i index.IndexReader, m mapping.IndexMapping, optio
 	if q.FieldVal == "" {
 		field = m.DefaultSearchField()
 	}
+	if IsFieldGlob(field) {
+		return fieldGlobSearcher(i, field, options,
+			func(field string) (search.Searcher, error) {
+				qc := *q
+				qc.FieldVal = field
+				return qc.Searcher(i, m, options)
+			})
+	}
 
 	analyzerName := ""
 	if q.Analyzer != "" {
@@ -76,6 +76,14 @@ func (q *NumericRangeQuery) Searcher(i index.IndexReader, m mapping.IndexMapping
 	if q.FieldVal == "" {
 		field = m.DefaultSearchField()
 	}
+	if IsFieldGlob(field) {
+		return fieldGlobSearcher(i, field, options,
+			func(field string) (search.Searcher, error) {
+				qc := *q
+				qc.FieldVal = field
+				return qc.Searcher(i, m, options)
+			})
+	}
 	return searcher.NewNumericRangeSearcher(i, q.Min, q.Max, q.InclusiveMin, q.InclusiveMax, field, q.BoostVal.Value(), options)
 }
 
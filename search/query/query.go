@@ -71,6 +71,9 @@ func ParseQuery(input []byte) (Query, error) {
 	if err != nil {
 		return nil, err
 	}
+	if rv, ok, err := parseCustomQuery(input, tmp); ok {
+		return rv, err
+	}
 	_, isMatchQuery := tmp["match"]
 	_, hasFuzziness := tmp["fuzziness"]
 	if hasFuzziness && !isMatchQuery {
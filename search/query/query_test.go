@@ -15,12 +15,15 @@
 package query
 
 import (
+	"encoding/json"
 	"reflect"
 	"strings"
 	"testing"
 	"time"
 
+	"github.com/blevesearch/bleve/index"
 	"github.com/blevesearch/bleve/mapping"
+	"github.com/blevesearch/bleve/search"
 )
 
 var minNum = 5.1
@@ -421,3 +424,78 @@ func TestDumpQuery(t *testing.T) {
 		t.Fatalf("query:\n%s\ndiffers from expected:\n%s", s, wanted)
 	}
 }
+
+func TestMatchFieldGlob(t *testing.T) {
+	tests := []struct {
+		glob    string
+		field   string
+		matches bool
+	}{
+		{glob: "user.*", field: "user.name", matches: true},
+		{glob: "user.*", field: "user.address.city", matches: true},
+		{glob: "user.*", field: "username", matches: false},
+		{glob: "*.name", field: "user.name", matches: true},
+		{glob: "*", field: "_all", matches: false},
+		{glob: "_*", field: "_all", matches: true},
+		{glob: "user.[", field: "user.[", matches: false},
+	}
+	for _, test := range tests {
+		if IsFieldGlob(test.glob) != strings.Contains(test.glob, "*") {
+			t.Errorf("%s: unexpected IsFieldGlob", test.glob)
+		}
+		if actual := MatchFieldGlob(test.glob, test.field); actual != test.matches {
+			t.Errorf("%s on %s: expected %t, got %t",
+				test.glob, test.field, test.matches, actual)
+		}
+	}
+}
+
+type testCustomQuery struct {
+	Custom string `json:"custom"`
+}
+
+func (q *testCustomQuery) Searcher(i index.IndexReader, m mapping.IndexMapping,
+	options search.SearcherOptions) (search.Searcher, error) {
+	return NewTermQuery(q.Custom).Searcher(i, m, options)
+}
+
+func TestParseCustomQuery(t *testing.T) {
+	RegisterQueryType("custom", func(input []byte) (Query, error) {
+		var rv testCustomQuery
+		err := json.Unmarshal(input, &rv)
+		if err != nil {
+			return nil, err
+		}
+		return &rv, nil
+	})
+	defer func() {
+		customQueryTypesMutex.Lock()
+		delete(customQueryTypes, "custom")
+		customQueryTypesMutex.Unlock()
+	}()
+
+	actual, err := ParseQuery([]byte(`{"must":{"conjuncts":[{"custom":"beer"}]},` +
+		`"should":{"disjuncts":[{"custom":"wine"},{"term":"water"}]}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := NewBooleanQuery(
+		[]Query{&testCustomQuery{Custom: "beer"}},
+		[]Query{&testCustomQuery{Custom: "wine"}, NewTermQuery("water")},
+		nil)
+	if !reflect.DeepEqual(actual, expected) {
+		t.Errorf("expected %#v, got %#v", expected, actual)
+	}
+
+	_, err = ParseQuery([]byte(`{"custom":1}`))
+	if err == nil {
+		t.Errorf("expected error unmarshaling invalid custom query")
+	}
+
+	defer func() {
+		if r := recover(); r == nil {
+			t.Errorf("expected panic registering duplicate query type")
+		}
+	}()
+	RegisterQueryType("custom", nil)
+}
@@ -57,5 +57,13 @@ func (q *TermQuery) Searcher(i index.IndexReader, m mapping.IndexMapping, option
 	if q.FieldVal == "" {
 		field = m.DefaultSearchField()
 	}
+	if IsFieldGlob(field) {
+		return fieldGlobSearcher(i, field, options,
+			func(field string) (search.Searcher, error) {
+				qc := *q
+				qc.FieldVal = field
+				return qc.Searcher(i, m, options)
+			})
+	}
 	return searcher.NewTermSearcher(i, q.Term, field, q.BoostVal.Value(), options)
 }
@@ -76,6 +76,14 @@ func (q *TermRangeQuery) Searcher(i index.IndexReader, m mapping.IndexMapping, o
 	if q.FieldVal == "" {
 		field = m.DefaultSearchField()
 	}
+	if IsFieldGlob(field) {
+		return fieldGlobSearcher(i, field, options,
+			func(field string) (search.Searcher, error) {
+				qc := *q
+				qc.FieldVal = field
+				return qc.Searcher(i, m, options)
+			})
+	}
 	var minTerm []byte
 	if q.Min != "" {
 		minTerm = []byte(q.Min)
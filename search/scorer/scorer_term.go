@@ -112,6 +112,16 @@ func (s *TermQueryScorer) SetQueryNorm(qnorm float64) {
 	}
 }
 
+// ScoreBound returns an upper bound on the score of a match, given an
+// upper bound on its impact, sqrt(freq) * norm.
+func (s *TermQueryScorer) ScoreBound(impact float64) float64 {
+	score := impact * s.idf
+	if s.queryWeight != 1.0 {
+		score = score * s.queryWeight
+	}
+	return score
+}
+
 func (s *TermQueryScorer) Score(ctx *search.SearchContext, termMatch *index.TermFieldDoc) *search.DocumentMatch {
 	var scoreExplanation *search.Explanation
 
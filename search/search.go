@@ -349,6 +349,24 @@ type Searcher interface {
 	DocumentMatchPoolSize() int
 }
 
+// ScoreBoundedSearcher is an optional interface implemented by searchers
+// able to report upper bounds on the scores of the matches they return.
+type ScoreBoundedSearcher interface {
+	// MaxScore returns an upper bound on the score of any match.
+	MaxScore() float64
+
+	// BlockMaxScore returns an upper bound on the score of the matches
+	// in the block containing the ID, along with the ID where the
+	// following block starts, or nil if there is no following block.
+	BlockMaxScore(ID index.IndexInternalID) (float64, index.IndexInternalID, error)
+}
+
+// PlannedSearcher is an optional interface implemented by searchers
+// able to describe their plan.
+type PlannedSearcher interface {
+	Plan() *Plan
+}
+
 type SearcherOptions struct {
 	Explain            bool
 	IncludeTermVectors bool
@@ -0,0 +1,239 @@
+//  Copyright (c) 2019 Couchbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 		http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package searcher
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/search"
+)
+
+// ConjunctionBitmapMaxSkew is a compile time setting that applications
+// can adjust to control when a conjunction stops intersecting the
+// bitmaps of its term clauses up-front.  When its cheapest clause is
+// estimated to be this many times cheaper than the next one, driving
+// the other clauses from its postings touches less data.
+var ConjunctionBitmapMaxSkew = uint64(128)
+
+// ExplainPlan returns the plan of the searcher, which for searchers not
+// implementing search.PlannedSearcher is just their type and count.
+func ExplainPlan(s search.Searcher) *search.Plan {
+	if ps, ok := s.(search.PlannedSearcher); ok {
+		return ps.Plan()
+	}
+	name := strings.TrimSuffix(fmt.Sprintf("%T", s), "Searcher")
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		name = name[i+1:]
+	}
+	return &search.Plan{
+		Searcher: strings.ToLower(name),
+		Cost:     s.Count(),
+	}
+}
+
+func explainPlans(searchers []search.Searcher) []*search.Plan {
+	rv := make([]*search.Plan, len(searchers))
+	for i, s := range searchers {
+		rv[i] = ExplainPlan(s)
+	}
+	return rv
+}
+
+// sortByCost orders the searchers cheapest first, returning their costs
+func sortByCost(searchers []search.Searcher) []uint64 {
+	costs := make([]uint64, len(searchers))
+	for i, s := range searchers {
+		costs[i] = ExplainPlan(s).Cost
+	}
+	sort.Stable(&searchersByCost{searchers: searchers, costs: costs})
+	return costs
+}
+
+type searchersByCost struct {
+	searchers []search.Searcher
+	costs     []uint64
+}
+
+func (s *searchersByCost) Len() int { return len(s.searchers) }
+
+func (s *searchersByCost) Less(i, j int) bool { return s.costs[i] < s.costs[j] }
+
+func (s *searchersByCost) Swap(i, j int) {
+	s.searchers[i], s.searchers[j] = s.searchers[j], s.searchers[i]
+	s.costs[i], s.costs[j] = s.costs[j], s.costs[i]
+}
+
+// useConjunctionBitmaps decides, from the sorted costs of its clauses,
+// whether a conjunction should intersect the bitmaps of its term
+// clauses rather than leapfrog over their postings
+func useConjunctionBitmaps(costs []uint64) bool {
+	if len(costs) < 2 {
+		return false
+	}
+	return costs[0]*ConjunctionBitmapMaxSkew >= costs[1]
+}
+
+// bitmapClauses returns the clauses of a conjunction whose postings
+// bitmaps can be intersected up-front
+func bitmapClauses(searchers []search.Searcher) (rv, others []search.Searcher) {
+	for _, s := range searchers {
+		if isBitmapClause(s) {
+			rv = append(rv, s)
+		} else {
+			others = append(others, s)
+		}
+	}
+	return rv, others
+}
+
+func isBitmapClause(s search.Searcher) bool {
+	switch s := s.(type) {
+	case *TermSearcher:
+		_, ok := s.reader.(index.Optimizable)
+		return ok
+	case *DisjunctionSliceSearcher:
+		return len(s.searchers) == 1 && isBitmapClause(s.searchers[0])
+	case *DisjunctionHeapSearcher:
+		return len(s.searchers) == 1 && isBitmapClause(s.searchers[0])
+	}
+	return false
+}
+
+func (s *TermSearcher) Plan() *search.Plan {
+	term := string(s.term)
+	if s.field == "*" && strings.HasSuffix(term, ":unadorned") {
+		// the bitmaps of several clauses, combined up-front
+		return &search.Plan{
+			Searcher: strings.TrimSuffix(term, ":unadorned"),
+			Strategy: "bitmap",
+			Cost:     s.Count(),
+		}
+	}
+	return &search.Plan{
+		Searcher: "term",
+		Strategy: "postings",
+		Field:    s.field,
+		Term:     term,
+		Cost:     s.Count(),
+	}
+}
+
+func (s *PointsSearcher) Plan() *search.Plan {
+	return &search.Plan{
+		Searcher: "points",
+		Strategy: "bkd",
+		Field:    s.field,
+		Cost:     s.Count(),
+	}
+}
+
+func (s *ConjunctionSearcher) Plan() *search.Plan {
+	rv := &search.Plan{
+		Searcher: "conjunction",
+		Strategy: s.strategy,
+		Children: explainPlans(s.searchers),
+	}
+	for i, child := range rv.Children {
+		if i == 0 || child.Cost < rv.Cost {
+			rv.Cost = child.Cost
+		}
+	}
+	return rv
+}
+
+func disjunctionPlan(strategy string, searchers []search.Searcher,
+	min int) *search.Plan {
+	rv := &search.Plan{
+		Searcher: "disjunction",
+		Strategy: strategy,
+		Children: explainPlans(searchers),
+	}
+	for _, child := range rv.Children {
+		rv.Cost += child.Cost
+	}
+	if min > 1 {
+		rv.Cost /= uint64(min)
+	}
+	return rv
+}
+
+func (s *DisjunctionSliceSearcher) Plan() *search.Plan {
+	return disjunctionPlan("slice", s.searchers, s.min)
+}
+
+func (s *DisjunctionHeapSearcher) Plan() *search.Plan {
+	return disjunctionPlan("heap", s.searchers, s.min)
+}
+
+func (s *DisjunctionWANDSearcher) Plan() *search.Plan {
+	return disjunctionPlan("wand", s.searchers, 0)
+}
+
+func (s *BooleanSearcher) Plan() *search.Plan {
+	rv := &search.Plan{
+		Searcher: "boolean",
+	}
+	var mustCost, shouldCost uint64
+	if s.mustSearcher != nil {
+		must := ExplainPlan(s.mustSearcher)
+		mustCost = must.Cost
+		rv.Children = append(rv.Children, must)
+	}
+	if s.shouldSearcher != nil {
+		should := ExplainPlan(s.shouldSearcher)
+		shouldCost = should.Cost
+		rv.Children = append(rv.Children, should)
+	}
+	if s.mustNotSearcher != nil {
+		rv.Children = append(rv.Children, ExplainPlan(s.mustNotSearcher))
+	}
+	switch {
+	case s.mustSearcher != nil && s.shouldSearcher != nil &&
+		s.shouldSearcher.Min() > 0:
+		rv.Strategy = "must+should"
+		rv.Cost = mustCost
+		if shouldCost < rv.Cost {
+			rv.Cost = shouldCost
+		}
+	case s.mustSearcher != nil:
+		rv.Strategy = "must"
+		rv.Cost = mustCost
+	default:
+		rv.Strategy = "should"
+		rv.Cost = shouldCost
+	}
+	return rv
+}
+
+func (s *PhraseSearcher) Plan() *search.Plan {
+	must := ExplainPlan(s.mustSearcher)
+	return &search.Plan{
+		Searcher: "phrase",
+		Cost:     must.Cost,
+		Children: []*search.Plan{must},
+	}
+}
+
+func (s *FilteringSearcher) Plan() *search.Plan {
+	child := ExplainPlan(s.child)
+	return &search.Plan{
+		Searcher: "filtering",
+		Cost:     child.Cost,
+		Children: []*search.Plan{child},
+	}
+}
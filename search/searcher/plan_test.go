@@ -0,0 +1,184 @@
+//  Copyright (c) 2019 Couchbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 		http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package searcher
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/blevesearch/bleve/document"
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/index/scorch"
+	"github.com/blevesearch/bleve/search"
+)
+
+func TestConjunctionPlan(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "scorchPlan")
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	idx, err := scorch.NewScorch(scorch.Name,
+		map[string]interface{}{
+			"path": dir,
+		}, index.NewAnalysisQueue(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = idx.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := idx.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	batch := index.NewBatch()
+	for i := 0; i < 100; i++ {
+		desc := []string{"common"}
+		if i%50 == 0 {
+			desc = append(desc, "rare")
+		}
+		if i%2 == 0 {
+			desc = append(desc, "even")
+		}
+		if i%3 == 0 {
+			desc = append(desc, "third")
+		}
+		batch.Update(document.NewDocument(fmt.Sprintf("%d", i)).
+			AddField(document.NewTextFieldWithAnalyzer("desc", []uint64{},
+				[]byte(strings.Join(desc, " ")), testAnalyzer)))
+	}
+	err = idx.Batch(batch)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reader, err := idx.Reader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := reader.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	termSearchers := func(options search.SearcherOptions,
+		terms ...string) []search.Searcher {
+		var rv []search.Searcher
+		for _, term := range terms {
+			ts, err := NewTermSearcher(reader, term, "desc", 1.0, options)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rv = append(rv, ts)
+		}
+		return rv
+	}
+
+	count := func(s search.Searcher) int {
+		ctx := &search.SearchContext{
+			DocumentMatchPool: search.NewDocumentMatchPool(s.DocumentMatchPoolSize(), 0),
+		}
+		var rv int
+		next, err := s.Next(ctx)
+		for err == nil && next != nil {
+			rv++
+			ctx.DocumentMatchPool.Put(next)
+			next, err = s.Next(ctx)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rv
+	}
+
+	// cheapest clauses first, with the term bitmaps intersected up-front
+	scored := search.SearcherOptions{Explain: true}
+	cs, err := NewConjunctionSearcher(reader,
+		termSearchers(scored, "common", "even", "rare"), scored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan := ExplainPlan(cs)
+	if plan.Searcher != "conjunction" || plan.Strategy != "leapfrog+bitmap" ||
+		plan.Cost != 2 {
+		t.Errorf("unexpected plan: %s", plan)
+	}
+	var terms []string
+	for _, child := range plan.Children {
+		terms = append(terms, child.Term)
+	}
+	if strings.Join(terms, ",") != "rare,even,common" {
+		t.Errorf("expected clauses ordered by cost, got %v", terms)
+	}
+	if n := count(cs); n != 2 {
+		t.Errorf("expected 2 hits, got %d", n)
+	}
+
+	// a selective enough clause drives the others
+	defaultSkew := ConjunctionBitmapMaxSkew
+	ConjunctionBitmapMaxSkew = 10
+	cs, err = NewConjunctionSearcher(reader,
+		termSearchers(scored, "common", "rare"), scored)
+	ConjunctionBitmapMaxSkew = defaultSkew
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan = ExplainPlan(cs)
+	if plan.Strategy != "leapfrog" {
+		t.Errorf("expected leapfrog strategy, got plan: %s", plan)
+	}
+	if n := count(cs); n != 2 {
+		t.Errorf("expected 2 hits, got %d", n)
+	}
+
+	// without scoring, the term clauses become a single bitmap
+	unscored := search.SearcherOptions{Score: "none"}
+	ds, err := NewDisjunctionSearcher(reader,
+		termSearchers(search.SearcherOptions{}, "rare", "third"), 0,
+		search.SearcherOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cs, err = NewConjunctionSearcher(reader,
+		append(termSearchers(unscored, "common", "even"), ds), unscored)
+	if err != nil {
+		t.Fatal(err)
+	}
+	plan = ExplainPlan(cs)
+	if plan.Strategy != "leapfrog" || len(plan.Children) != 2 {
+		t.Fatalf("unexpected plan: %s", plan)
+	}
+	var strategies []string
+	for _, child := range plan.Children {
+		strategies = append(strategies, child.Searcher+":"+child.Strategy)
+	}
+	if strings.Join(strategies, ",") != "conjunction:bitmap,disjunction:slice" {
+		t.Errorf("unexpected clause strategies: %v", strategies)
+	}
+	// even docs which are either rare or multiples of 3
+	if n := count(cs); n != 18 {
+		t.Errorf("expected 18 hits, got %d", n)
+	}
+}
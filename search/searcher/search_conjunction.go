@@ -17,7 +17,6 @@ package searcher
 import (
 	"math"
 	"reflect"
-	"sort"
 
 	"github.com/blevesearch/bleve/index"
 	"github.com/blevesearch/bleve/search"
@@ -41,26 +40,44 @@ type ConjunctionSearcher struct {
 	scorer      *scorer.ConjunctionQueryScorer
 	initialized bool
 	options     search.SearcherOptions
+	strategy    string
 }
 
 func NewConjunctionSearcher(indexReader index.IndexReader,
 	qsearchers []search.Searcher, options search.SearcherOptions) (
 	search.Searcher, error) {
-	// build the sorted downstream searchers
+	// build the downstream searchers, cheapest first
 	searchers := make(OrderedSearcherList, len(qsearchers))
 	for i, searcher := range qsearchers {
 		searchers[i] = searcher
 	}
-	sort.Sort(searchers)
+	costs := sortByCost(searchers)
+
+	// intersecting the bitmaps of the term clauses up-front pays off
+	// unless the cheapest clause alone is selective enough
+	useBitmaps := useConjunctionBitmaps(costs)
+	bitmapSearchers, otherSearchers := bitmapClauses(searchers)
+	if len(bitmapSearchers) < 2 {
+		useBitmaps = false
+	}
 
 	// attempt the "unadorned" conjunction optimization only when we
 	// do not need extra information like freq-norm's or term vectors
-	if len(searchers) > 1 &&
+	if useBitmaps &&
 		options.Score == "none" && !options.IncludeTermVectors {
 		rv, err := optimizeCompositeSearcher("conjunction:unadorned",
-			indexReader, searchers, options)
-		if err != nil || rv != nil {
-			return rv, err
+			indexReader, bitmapSearchers, options)
+		if err != nil {
+			return nil, err
+		}
+		if rv != nil {
+			if len(otherSearchers) == 0 {
+				return rv, nil
+			}
+			// the remaining clauses leapfrog over the combined bitmap
+			searchers = append(OrderedSearcherList{rv}, otherSearchers...)
+			sortByCost(searchers)
+			useBitmaps = false
 		}
 	}
 
@@ -71,15 +88,20 @@ func NewConjunctionSearcher(indexReader index.IndexReader,
 		searchers:   searchers,
 		currs:       make([]*search.DocumentMatch, len(searchers)),
 		scorer:      scorer.NewConjunctionQueryScorer(options),
+		strategy:    "leapfrog",
 	}
 	rv.computeQueryNorm()
 
-	// attempt push-down conjunction optimization when there's >1 searchers
-	if len(searchers) > 1 {
-		rv, err := optimizeCompositeSearcher("conjunction",
-			indexReader, searchers, options)
-		if err != nil || rv != nil {
-			return rv, err
+	// attempt push-down conjunction optimization of the term clauses
+	if useBitmaps {
+		rv.strategy = "leapfrog+bitmap"
+		optimized, err := optimizeCompositeSearcher("conjunction",
+			indexReader, bitmapSearchers, options)
+		if err != nil {
+			return nil, err
+		}
+		if optimized != nil && len(otherSearchers) == 0 {
+			return optimized, nil
 		}
 	}
 
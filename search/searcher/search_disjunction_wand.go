@@ -0,0 +1,388 @@
+//  Copyright (c) 2019 Couchbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 		http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package searcher
+
+import (
+	"reflect"
+
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/search"
+	"github.com/blevesearch/bleve/search/scorer"
+	"github.com/blevesearch/bleve/size"
+)
+
+var reflectStaticSizeDisjunctionWANDSearcher int
+
+func init() {
+	var ds DisjunctionWANDSearcher
+	reflectStaticSizeDisjunctionWANDSearcher = int(reflect.TypeOf(ds).Size())
+}
+
+// DisjunctionWANDSearcher is a disjunction searcher which skips the
+// matches that cannot score above the minimum competitive score
+// reported by the collector.  It implements the block-max WAND
+// algorithm, using the score upper bounds of its child searchers,
+// refined with their per block score upper bounds.
+type DisjunctionWANDSearcher struct {
+	indexReader  index.IndexReader
+	searchers    []search.Searcher
+	bounded      []search.ScoreBoundedSearcher
+	maxScores    []float64
+	numSearchers int
+	queryNorm    float64
+	currs        []*search.DocumentMatch
+	order        []int // indexes of searchers, sorted by their currs
+	scorer       *scorer.DisjunctionQueryScorer
+	matching     []*search.DocumentMatch
+	initialized  bool
+}
+
+// OptimizeForTopScores returns a searcher equivalent to the provided
+// one, except that it may skip the matches which cannot make it into
+// the results of a collector implementing the
+// search.CompetitiveScoreCollector interface.  The optimization is only
+// valid for a top-level searcher, whose scores are the final scores of
+// the hits, and for hits sorted by descending score.  Searchers which
+// cannot be optimized are returned unchanged.
+func OptimizeForTopScores(s search.Searcher) search.Searcher {
+	var indexReader index.IndexReader
+	var searchers []search.Searcher
+	var dscorer *scorer.DisjunctionQueryScorer
+	var queryNorm float64
+	switch ds := s.(type) {
+	case *DisjunctionSliceSearcher:
+		if ds.min > 1 || ds.initialized {
+			return s
+		}
+		indexReader = ds.indexReader
+		searchers = ds.searchers
+		dscorer = ds.scorer
+		queryNorm = ds.queryNorm
+	case *DisjunctionHeapSearcher:
+		if ds.min > 1 || ds.initialized {
+			return s
+		}
+		indexReader = ds.indexReader
+		searchers = ds.searchers
+		dscorer = ds.scorer
+		queryNorm = ds.queryNorm
+	default:
+		return s
+	}
+
+	bounded := make([]search.ScoreBoundedSearcher, len(searchers))
+	maxScores := make([]float64, len(searchers))
+	for i, searcher := range searchers {
+		b, ok := searcher.(search.ScoreBoundedSearcher)
+		if !ok {
+			return s
+		}
+		maxScore := b.MaxScore()
+		if !(maxScore >= 0) {
+			// negative boosts or unknown bounds (NaN) disable pruning
+			return s
+		}
+		bounded[i] = b
+		maxScores[i] = maxScore
+	}
+
+	order := make([]int, len(searchers))
+	for i := range order {
+		order[i] = i
+	}
+
+	return &DisjunctionWANDSearcher{
+		indexReader:  indexReader,
+		searchers:    searchers,
+		bounded:      bounded,
+		maxScores:    maxScores,
+		numSearchers: len(searchers),
+		queryNorm:    queryNorm,
+		currs:        make([]*search.DocumentMatch, len(searchers)),
+		order:        order,
+		scorer:       dscorer,
+		matching:     make([]*search.DocumentMatch, len(searchers)),
+	}
+}
+
+func (s *DisjunctionWANDSearcher) Size() int {
+	sizeInBytes := reflectStaticSizeDisjunctionWANDSearcher + size.SizeOfPtr +
+		s.scorer.Size()
+
+	for _, entry := range s.searchers {
+		sizeInBytes += entry.Size()
+	}
+
+	for _, entry := range s.currs {
+		if entry != nil {
+			sizeInBytes += entry.Size()
+		}
+	}
+
+	sizeInBytes += len(s.bounded) * size.SizeOfPtr
+	sizeInBytes += len(s.maxScores) * size.SizeOfFloat64
+	sizeInBytes += len(s.order) * size.SizeOfInt
+	sizeInBytes += len(s.matching) * size.SizeOfPtr
+
+	return sizeInBytes
+}
+
+func (s *DisjunctionWANDSearcher) initSearchers(ctx *search.SearchContext) error {
+	var err error
+	// get all searchers pointing at their first match
+	for i, searcher := range s.searchers {
+		if s.currs[i] != nil {
+			ctx.DocumentMatchPool.Put(s.currs[i])
+		}
+		s.currs[i], err = searcher.Next(ctx)
+		if err != nil {
+			return err
+		}
+	}
+	s.initialized = true
+	return nil
+}
+
+// sortOrder orders the searchers by the IDs of their current matches,
+// exhausted searchers last.
+func (s *DisjunctionWANDSearcher) sortOrder() {
+	for i := 1; i < len(s.order); i++ {
+		for j := i; j > 0 && s.less(s.order[j], s.order[j-1]); j-- {
+			s.order[j], s.order[j-1] = s.order[j-1], s.order[j]
+		}
+	}
+}
+
+func (s *DisjunctionWANDSearcher) less(i, j int) bool {
+	if s.currs[j] == nil {
+		return s.currs[i] != nil
+	}
+	if s.currs[i] == nil {
+		return false
+	}
+	return s.currs[i].IndexInternalID.Compare(s.currs[j].IndexInternalID) < 0
+}
+
+func (s *DisjunctionWANDSearcher) minCompetitiveScore(ctx *search.SearchContext) (
+	float64, bool) {
+	if ctx == nil {
+		return 0, false
+	}
+	c, ok := ctx.Collector.(search.CompetitiveScoreCollector)
+	if !ok {
+		return 0, false
+	}
+	return c.MinCompetitiveScore()
+}
+
+func (s *DisjunctionWANDSearcher) Weight() float64 {
+	var rv float64
+	for _, searcher := range s.searchers {
+		rv += searcher.Weight()
+	}
+	return rv
+}
+
+func (s *DisjunctionWANDSearcher) SetQueryNorm(qnorm float64) {
+	for _, searcher := range s.searchers {
+		searcher.SetQueryNorm(qnorm)
+	}
+}
+
+func (s *DisjunctionWANDSearcher) Next(ctx *search.SearchContext) (
+	*search.DocumentMatch, error) {
+	if !s.initialized {
+		err := s.initSearchers(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for {
+		s.sortOrder()
+		if len(s.order) == 0 || s.currs[s.order[0]] == nil {
+			return nil, nil
+		}
+
+		threshold, ok := s.minCompetitiveScore(ctx)
+		if !ok {
+			return s.nextMatch(ctx, s.currs[s.order[0]].IndexInternalID)
+		}
+
+		// the pivot is the first searcher at which the sum of the score
+		// upper bounds exceeds the threshold, so no document before the
+		// pivot's current match can be competitive
+		pivot := -1
+		var sum float64
+		for p, i := range s.order {
+			if s.currs[i] == nil {
+				break
+			}
+			sum += s.maxScores[i]
+			if sum > threshold {
+				pivot = p
+				break
+			}
+		}
+		if pivot < 0 {
+			return nil, nil
+		}
+		pivotID := s.currs[s.order[pivot]].IndexInternalID
+
+		// extend to the searchers positioned on the pivot's document
+		last := pivot
+		for last+1 < len(s.order) && s.currs[s.order[last+1]] != nil &&
+			s.currs[s.order[last+1]].IndexInternalID.Equals(pivotID) {
+			last++
+		}
+
+		// refine the bound with the blocks containing the pivot's document
+		var blockSum float64
+		var target index.IndexInternalID
+		for _, i := range s.order[:last+1] {
+			blockMax, nextBlock, err := s.bounded[i].BlockMaxScore(pivotID)
+			if err != nil {
+				return nil, err
+			}
+			blockSum += blockMax
+			if nextBlock != nil && (target == nil || nextBlock.Compare(target) < 0) {
+				target = nextBlock
+			}
+		}
+		if blockSum <= threshold {
+			// nothing is competitive until one of these blocks ends, or
+			// another searcher joins in
+			if last+1 < len(s.order) && s.currs[s.order[last+1]] != nil {
+				nextID := s.currs[s.order[last+1]].IndexInternalID
+				if target == nil || nextID.Compare(target) < 0 {
+					target = nextID
+				}
+			}
+			if target == nil {
+				return nil, nil
+			}
+			err := s.advanceTo(ctx, s.order[:last+1], target)
+			if err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if s.currs[s.order[0]].IndexInternalID.Equals(pivotID) {
+			return s.nextMatch(ctx, pivotID)
+		}
+
+		err := s.advanceTo(ctx, s.order[:pivot], pivotID)
+		if err != nil {
+			return nil, err
+		}
+	}
+}
+
+// nextMatch scores the searchers' current matches for the provided
+// ID, and moves those searchers to their next match.
+func (s *DisjunctionWANDSearcher) nextMatch(ctx *search.SearchContext,
+	ID index.IndexInternalID) (*search.DocumentMatch, error) {
+	matching := s.matching[:0]
+	for _, curr := range s.currs {
+		if curr != nil && curr.IndexInternalID.Equals(ID) {
+			matching = append(matching, curr)
+		}
+	}
+	s.matching = matching
+
+	rv := s.scorer.Score(ctx, matching, len(matching), s.numSearchers)
+
+	// invoke next on all the matching searchers
+	var err error
+	for i, curr := range s.currs {
+		if curr == nil || !curr.IndexInternalID.Equals(rv.IndexInternalID) {
+			continue
+		}
+		if curr != rv {
+			ctx.DocumentMatchPool.Put(curr)
+		}
+		s.currs[i], err = s.searchers[i].Next(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return rv, nil
+}
+
+// advanceTo moves the provided searchers to their first match at or
+// after the provided ID.
+func (s *DisjunctionWANDSearcher) advanceTo(ctx *search.SearchContext,
+	idxs []int, ID index.IndexInternalID) error {
+	var err error
+	for _, i := range idxs {
+		if s.currs[i] == nil || s.currs[i].IndexInternalID.Compare(ID) >= 0 {
+			continue
+		}
+		ctx.DocumentMatchPool.Put(s.currs[i])
+		s.currs[i], err = s.searchers[i].Advance(ctx, ID)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *DisjunctionWANDSearcher) Advance(ctx *search.SearchContext,
+	ID index.IndexInternalID) (*search.DocumentMatch, error) {
+	if !s.initialized {
+		err := s.initSearchers(ctx)
+		if err != nil {
+			return nil, err
+		}
+	}
+	err := s.advanceTo(ctx, s.order, ID)
+	if err != nil {
+		return nil, err
+	}
+	return s.Next(ctx)
+}
+
+func (s *DisjunctionWANDSearcher) Count() uint64 {
+	// for now return a worst case
+	var sum uint64
+	for _, searcher := range s.searchers {
+		sum += searcher.Count()
+	}
+	return sum
+}
+
+func (s *DisjunctionWANDSearcher) Close() (rv error) {
+	for _, searcher := range s.searchers {
+		err := searcher.Close()
+		if err != nil && rv == nil {
+			rv = err
+		}
+	}
+	return rv
+}
+
+func (s *DisjunctionWANDSearcher) Min() int {
+	return 0
+}
+
+func (s *DisjunctionWANDSearcher) DocumentMatchPoolSize() int {
+	rv := len(s.currs)
+	for _, s := range s.searchers {
+		rv += s.DocumentMatchPoolSize()
+	}
+	return rv
+}
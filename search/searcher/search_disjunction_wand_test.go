@@ -0,0 +1,172 @@
+//  Copyright (c) 2019 Couchbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 		http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package searcher
+
+import (
+	"context"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"strings"
+	"testing"
+
+	"github.com/blevesearch/bleve/document"
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/index/scorch"
+	"github.com/blevesearch/bleve/search"
+	"github.com/blevesearch/bleve/search/collector"
+)
+
+func TestDisjunctionWANDSearch(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "scorchWAND")
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	// small chunks, so that block level bounds come into play
+	defaultChunkFactor := scorch.DefaultChunkFactor
+	scorch.DefaultChunkFactor = 8
+	defer func() {
+		scorch.DefaultChunkFactor = defaultChunkFactor
+	}()
+
+	idx, err := scorch.NewScorch(scorch.Name,
+		map[string]interface{}{
+			"path": dir,
+		}, index.NewAnalysisQueue(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = idx.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := idx.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	words := []string{"alpha", "beta", "gamma", "delta", "epsilon"}
+	seed := uint32(1)
+	for b := 0; b < 4; b++ {
+		batch := index.NewBatch()
+		for i := 0; i < 100; i++ {
+			var desc []string
+			for j := 0; j < 8; j++ {
+				seed = seed*1103515245 + 12345
+				// skew the word frequencies, so that the term bounds differ
+				n := int(seed>>16) % (len(words) * len(words))
+				desc = append(desc, words[len(words)-1-sqrtInt(n)])
+			}
+			batch.Update(document.NewDocument(fmt.Sprintf("%d-%d", b, i)).
+				AddField(document.NewTextFieldWithAnalyzer("desc", []uint64{},
+					[]byte(strings.Join(desc, " ")), testAnalyzer)))
+		}
+		err = idx.Batch(batch)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reader, err := idx.Reader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := reader.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	tests := [][]string{
+		{"alpha"},
+		{"alpha", "beta"},
+		{"alpha", "beta", "gamma"},
+		{"alpha", "epsilon"},
+		{"alpha", "beta", "gamma", "delta", "epsilon"},
+		{"alpha", "missing"},
+	}
+
+	for _, terms := range tests {
+		for _, size := range []int{1, 5, 20} {
+			expected, expectedTotal := searchTopScores(t, reader, terms, size, false)
+			actual, actualTotal := searchTopScores(t, reader, terms, size, true)
+
+			if len(actual) != len(expected) {
+				t.Fatalf("terms: %v, size: %d, expected %d hits, got %d",
+					terms, size, len(expected), len(actual))
+			}
+			for i := range expected {
+				if !expected[i].IndexInternalID.Equals(actual[i].IndexInternalID) ||
+					!scoresCloseEnough(expected[i].Score, actual[i].Score) {
+					t.Errorf("terms: %v, size: %d, hit %d, expected %s (%f), got %s (%f)",
+						terms, size, i, expected[i].ID, expected[i].Score,
+						actual[i].ID, actual[i].Score)
+				}
+			}
+			if actualTotal > expectedTotal {
+				t.Errorf("terms: %v, size: %d, total %d exceeds %d",
+					terms, size, actualTotal, expectedTotal)
+			}
+		}
+	}
+}
+
+func sqrtInt(n int) int {
+	rv := 0
+	for (rv+1)*(rv+1) <= n {
+		rv++
+	}
+	return rv
+}
+
+func searchTopScores(t *testing.T, reader index.IndexReader, terms []string,
+	size int, optimize bool) (search.DocumentMatchCollection, uint64) {
+	searchers := make([]search.Searcher, 0, len(terms))
+	for _, term := range terms {
+		ts, err := NewTermSearcher(reader, term, "desc", 1.0, search.SearcherOptions{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		searchers = append(searchers, ts)
+	}
+	var s search.Searcher
+	s, err := NewDisjunctionSearcher(reader, searchers, 0, search.SearcherOptions{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if optimize {
+		s = OptimizeForTopScores(s)
+		if _, ok := s.(*DisjunctionWANDSearcher); !ok {
+			t.Fatalf("expected disjunction wand searcher, got %T", s)
+		}
+	}
+	defer func() {
+		err := s.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	c := collector.NewTopNCollector(size, 0, search.SortOrder{&search.SortScore{Desc: true}})
+	err = c.Collect(context.Background(), s, reader)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return c.Results(), c.Total()
+}
@@ -45,6 +45,15 @@ func NewGeoBoundingBoxSearcher(indexReader index.IndexReader, minLon, minLat,
 		return nil, err
 	}
 
+	if pr, ok := pointsIndexReader(indexReader,
+		len(onBoundaryTerms)+len(notOnBoundaryTerms), options); ok {
+		reader, err := pr.GeoBoundingBoxReader(field, minLon, minLat, maxLon, maxLat)
+		if err != nil {
+			return nil, err
+		}
+		return newPointsSearcher(reader, field, boost, options), nil
+	}
+
 	var onBoundarySearcher search.Searcher
 	dvReader, err := indexReader.DocValueReader([]string{field})
 	if err != nil {
@@ -56,6 +56,14 @@ func NewNumericRangeSearcher(indexReader index.IndexReader,
 	// FIXME hard-coded precision, should match field declaration
 	termRanges := splitInt64Range(minInt64, maxInt64, 4)
 	terms := termRanges.Enumerate()
+	if pr, ok := pointsIndexReader(indexReader, len(terms), options); ok &&
+		minInt64 <= maxInt64 {
+		reader, err := pr.NumericRangeReader(field, minInt64, maxInt64)
+		if err != nil {
+			return nil, err
+		}
+		return newPointsSearcher(reader, field, boost, options), nil
+	}
 	if len(terms) < 1 {
 		// cannot return MatchNoneSearcher because of interaction with
 		// commit f391b991c20f02681bacd197afc6d8aed444e132
@@ -0,0 +1,128 @@
+//  Copyright (c) 2019 Couchbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 		http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package searcher
+
+import (
+	"reflect"
+
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/search"
+	"github.com/blevesearch/bleve/search/scorer"
+	"github.com/blevesearch/bleve/size"
+)
+
+var reflectStaticSizePointsSearcher int
+
+func init() {
+	var ps PointsSearcher
+	reflectStaticSizePointsSearcher = int(reflect.TypeOf(ps).Size())
+}
+
+// UsePoints is a compile time setting that applications can adjust to
+// stop numeric range and geo bounding box searchers from using the
+// point indexes of the IndexReaders implementing index.IndexReaderPoints
+var UsePoints = true
+
+// PointsMinTerms is a compile time setting that applications can adjust
+// to control when numeric range and geo bounding box searchers use the
+// point indexes: when scoring is disabled, or when they would otherwise
+// search more than this many prefix coded terms.  As the point indexes
+// know nothing about terms, their documents get a constant score.
+var PointsMinTerms = 64
+
+// pointsIndexReader returns the IndexReaderPoints to use for a searcher
+// which would otherwise search numTerms terms
+func pointsIndexReader(indexReader index.IndexReader, numTerms int,
+	options search.SearcherOptions) (index.IndexReaderPoints, bool) {
+	pr, ok := indexReader.(index.IndexReaderPoints)
+	if !ok || !UsePoints {
+		return nil, false
+	}
+	return pr, options.Score == "none" || numTerms > PointsMinTerms
+}
+
+// PointsSearcher is a constant score searcher of the documents read
+// from the point index of a field, see index.IndexReaderPoints
+type PointsSearcher struct {
+	field  string
+	reader index.TermFieldReader
+	scorer *scorer.ConstantScorer
+	tfd    index.TermFieldDoc
+}
+
+func newPointsSearcher(reader index.TermFieldReader, field string,
+	boost float64, options search.SearcherOptions) *PointsSearcher {
+	return &PointsSearcher{
+		field:  field,
+		reader: reader,
+		scorer: scorer.NewConstantScorer(1.0, boost, options),
+	}
+}
+
+func (s *PointsSearcher) Size() int {
+	return reflectStaticSizePointsSearcher + size.SizeOfPtr +
+		len(s.field) +
+		s.reader.Size() +
+		s.scorer.Size() +
+		s.tfd.Size()
+}
+
+func (s *PointsSearcher) Count() uint64 {
+	return s.reader.Count()
+}
+
+func (s *PointsSearcher) Weight() float64 {
+	return s.scorer.Weight()
+}
+
+func (s *PointsSearcher) SetQueryNorm(qnorm float64) {
+	s.scorer.SetQueryNorm(qnorm)
+}
+
+func (s *PointsSearcher) Next(ctx *search.SearchContext) (*search.DocumentMatch, error) {
+	tfd, err := s.reader.Next(s.tfd.Reset())
+	if err != nil || tfd == nil {
+		return nil, err
+	}
+
+	return s.score(ctx, tfd), nil
+}
+
+func (s *PointsSearcher) Advance(ctx *search.SearchContext, ID index.IndexInternalID) (*search.DocumentMatch, error) {
+	tfd, err := s.reader.Advance(ID, s.tfd.Reset())
+	if err != nil || tfd == nil {
+		return nil, err
+	}
+
+	return s.score(ctx, tfd), nil
+}
+
+// score copies the ID of the reused TermFieldDoc into the DocumentMatch
+func (s *PointsSearcher) score(ctx *search.SearchContext,
+	tfd *index.TermFieldDoc) *search.DocumentMatch {
+	return s.scorer.Score(ctx, append(index.IndexInternalID(nil), tfd.ID...))
+}
+
+func (s *PointsSearcher) Close() error {
+	return s.reader.Close()
+}
+
+func (s *PointsSearcher) Min() int {
+	return 0
+}
+
+func (s *PointsSearcher) DocumentMatchPoolSize() int {
+	return 1
+}
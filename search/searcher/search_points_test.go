@@ -0,0 +1,162 @@
+//  Copyright (c) 2019 Couchbase, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+// 		http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package searcher
+
+import (
+	"fmt"
+	"io/ioutil"
+	"os"
+	"reflect"
+	"testing"
+
+	"github.com/blevesearch/bleve/document"
+	"github.com/blevesearch/bleve/index"
+	"github.com/blevesearch/bleve/index/scorch"
+	"github.com/blevesearch/bleve/search"
+)
+
+func TestPointsSearcher(t *testing.T) {
+	dir, _ := ioutil.TempDir("", "scorchPoints")
+	defer func() {
+		_ = os.RemoveAll(dir)
+	}()
+
+	idx, err := scorch.NewScorch(scorch.Name,
+		map[string]interface{}{
+			"path": dir,
+		}, index.NewAnalysisQueue(1))
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = idx.Open()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := idx.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// several batches, so several segments, with some deletions
+	for b := 0; b < 3; b++ {
+		batch := index.NewBatch()
+		for i := 0; i < 200; i++ {
+			n := b*200 + i
+			doc := document.NewDocument(fmt.Sprintf("%d", n))
+			doc.AddField(document.NewNumericField("n", []uint64{}, float64((n*37)%1001)-500))
+			if n%3 == 0 {
+				doc.AddField(document.NewNumericField("n", []uint64{1}, float64(n)))
+			}
+			doc.AddField(document.NewGeoPointField("loc", []uint64{},
+				float64((n*7)%360-180), float64((n*11)%180-90)))
+			batch.Update(doc)
+		}
+		for i := 0; i < b*10; i++ {
+			batch.Delete(fmt.Sprintf("%d", i*7))
+		}
+		err = idx.Batch(batch)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	reader, err := idx.Reader()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err := reader.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	hits := func(s search.Searcher, err error) []string {
+		if err != nil {
+			t.Fatal(err)
+		}
+		ctx := &search.SearchContext{
+			DocumentMatchPool: search.NewDocumentMatchPool(s.DocumentMatchPoolSize(), 0),
+		}
+		var rv []string
+		next, err := s.Next(ctx)
+		for err == nil && next != nil {
+			id, err := reader.ExternalID(next.IndexInternalID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			rv = append(rv, id)
+			ctx.DocumentMatchPool.Put(next)
+			next, err = s.Next(ctx)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+		err = s.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+		return rv
+	}
+
+	f := func(v float64) *float64 {
+		return &v
+	}
+	b := func(v bool) *bool {
+		return &v
+	}
+
+	options := search.SearcherOptions{Score: "none"}
+	searchers := []func() (search.Searcher, error){
+		func() (search.Searcher, error) {
+			return NewNumericRangeSearcher(reader, f(-100), f(250), nil, nil, "n", 1.0, options)
+		},
+		func() (search.Searcher, error) {
+			return NewNumericRangeSearcher(reader, f(-100), f(250), b(false), b(true), "n", 1.0, options)
+		},
+		func() (search.Searcher, error) {
+			return NewNumericRangeSearcher(reader, nil, f(-400), nil, nil, "n", 1.0, options)
+		},
+		func() (search.Searcher, error) {
+			return NewNumericRangeSearcher(reader, f(300), nil, nil, nil, "n", 1.0, options)
+		},
+		func() (search.Searcher, error) {
+			return NewGeoBoundingBoxSearcher(reader, -100, -30, 50, 60, "loc", 1.0, options, true)
+		},
+		func() (search.Searcher, error) {
+			return NewGeoBoundingBoxSearcher(reader, 0, 0, 40, 40, "loc", 1.0, options, true)
+		},
+	}
+	for i, newSearcher := range searchers {
+		s, err := newSearcher()
+		if _, ok := s.(*PointsSearcher); !ok {
+			t.Errorf("searcher %d: expected a points searcher, got %T", i, s)
+		}
+		actual := hits(s, err)
+
+		UsePoints = false
+		expected := hits(newSearcher())
+		UsePoints = true
+
+		if len(expected) == 0 {
+			t.Errorf("searcher %d: expected some hits", i)
+		}
+		if !reflect.DeepEqual(actual, expected) {
+			t.Errorf("searcher %d: expected %v, got %v", i, expected, actual)
+		}
+	}
+}
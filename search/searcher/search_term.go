@@ -15,6 +15,7 @@
 package searcher
 
 import (
+	"math"
 	"reflect"
 
 	"github.com/blevesearch/bleve/index"
@@ -35,6 +36,8 @@ type TermSearcher struct {
 	reader      index.TermFieldReader
 	scorer      *scorer.TermQueryScorer
 	tfd         index.TermFieldDoc
+	term        []byte
+	field       string
 }
 
 func NewTermSearcher(indexReader index.IndexReader, term string, field string, boost float64, options search.SearcherOptions) (*TermSearcher, error) {
@@ -62,6 +65,8 @@ func newTermSearcherFromReader(indexReader index.IndexReader, reader index.TermF
 		indexReader: indexReader,
 		reader:      reader,
 		scorer:      scorer,
+		term:        term,
+		field:       field,
 	}, nil
 }
 
@@ -69,7 +74,8 @@ func (s *TermSearcher) Size() int {
 	return reflectStaticSizeTermSearcher + size.SizeOfPtr +
 		s.reader.Size() +
 		s.tfd.Size() +
-		s.scorer.Size()
+		s.scorer.Size() +
+		len(s.term) + len(s.field)
 }
 
 func (s *TermSearcher) Count() uint64 {
@@ -84,6 +90,27 @@ func (s *TermSearcher) SetQueryNorm(qnorm float64) {
 	s.scorer.SetQueryNorm(qnorm)
 }
 
+func (s *TermSearcher) MaxScore() float64 {
+	impacts, ok := s.reader.(index.TermFieldReaderImpacts)
+	if !ok {
+		return math.Inf(1)
+	}
+	return s.scorer.ScoreBound(impacts.MaxImpact())
+}
+
+func (s *TermSearcher) BlockMaxScore(ID index.IndexInternalID) (
+	float64, index.IndexInternalID, error) {
+	impacts, ok := s.reader.(index.TermFieldReaderImpacts)
+	if !ok {
+		return math.Inf(1), nil, nil
+	}
+	impact, nextBlock, err := impacts.BlockMaxImpact(ID)
+	if err != nil {
+		return 0, nil, err
+	}
+	return s.scorer.ScoreBound(impact), nextBlock, nil
+}
+
 func (s *TermSearcher) Next(ctx *search.SearchContext) (*search.DocumentMatch, error) {
 	termMatch, err := s.reader.Next(s.tfd.Reset())
 	if err != nil {
@@ -23,6 +23,7 @@ import (
 	"strings"
 
 	"github.com/blevesearch/bleve/geo"
+	"github.com/blevesearch/bleve/index"
 	"github.com/blevesearch/bleve/numeric"
 )
 
@@ -41,6 +42,13 @@ type SearchSort interface {
 	Copy() SearchSort
 }
 
+// SummarizedSearchSort is implemented by the SearchSorts which can
+// compute their value from the summary of the terms of a field, rather
+// than visiting all its terms
+type SummarizedSearchSort interface {
+	UpdateSummary(field string, summary *index.DocValueSummary)
+}
+
 func ParseSearchSortObj(input map[string]interface{}) (SearchSort, error) {
 	descending, ok := input["desc"].(bool)
 	by, ok := input["by"].(string)
@@ -208,6 +216,28 @@ func (so SortOrder) UpdateVisitor(field string, term []byte) {
 	}
 }
 
+func (so SortOrder) UpdateSummary(field string, summary *index.DocValueSummary) {
+	for _, soi := range so {
+		if ssoi, ok := soi.(SummarizedSearchSort); ok {
+			ssoi.UpdateSummary(field, summary)
+		}
+	}
+}
+
+// Summarizable returns true if all the SearchSorts requiring fields can
+// compute their value from the summaries of the terms of those fields
+func (so SortOrder) Summarizable() bool {
+	for _, soi := range so {
+		if len(soi.RequiresFields()) == 0 {
+			continue
+		}
+		if _, ok := soi.(SummarizedSearchSort); !ok {
+			return false
+		}
+	}
+	return true
+}
+
 func (so SortOrder) Copy() SortOrder {
 	rv := make(SortOrder, len(so))
 	for i, soi := range so {
@@ -345,6 +375,8 @@ type SortField struct {
 	Missing SortFieldMissing
 	values  [][]byte
 	tmp     [][]byte
+
+	summarized bool
 }
 
 // UpdateVisitor notifies this sort field that in this document
@@ -355,13 +387,38 @@ func (s *SortField) UpdateVisitor(field string, term []byte) {
 	}
 }
 
+// UpdateSummary notifies this sort field of the summary of the terms
+// of this field in this document, which are then already filtered
+// by type
+func (s *SortField) UpdateSummary(field string, summary *index.DocValueSummary) {
+	if field != s.Field {
+		return
+	}
+	first, last := summary.First, summary.Last
+	if s.Type == SortFieldAsNumber || s.Type == SortFieldAsDate ||
+		(s.Type == SortFieldAuto && summary.AllPrefixCoded) {
+		first, last = summary.FirstShiftZero, summary.LastShiftZero
+	}
+	if first != nil {
+		s.values = append(s.values, first)
+		if !bytes.Equal(first, last) {
+			s.values = append(s.values, last)
+		}
+	}
+	s.summarized = true
+}
+
 // Value returns the sort value of the DocumentMatch
 // it also resets the state of this SortField for
 // processing the next document
 func (s *SortField) Value(i *DocumentMatch) string {
-	iTerms := s.filterTermsByType(s.values)
+	iTerms := s.values
+	if !s.summarized {
+		iTerms = s.filterTermsByType(s.values)
+	}
 	iTerm := s.filterTermsByMode(iTerms)
 	s.values = s.values[:0]
+	s.summarized = false
 	return iTerm
 }
 
@@ -617,6 +674,14 @@ func (s *SortGeoDistance) UpdateVisitor(field string, term []byte) {
 	}
 }
 
+// UpdateSummary notifies this sort field of the summary of the terms
+// of this field in this document
+func (s *SortGeoDistance) UpdateSummary(field string, summary *index.DocValueSummary) {
+	if field == s.Field && summary.FirstShiftZero != nil {
+		s.values = append(s.values, string(summary.FirstShiftZero))
+	}
+}
+
 // Value returns the sort value of the DocumentMatch
 // it also resets the state of this SortField for
 // processing the next document
@@ -32,12 +32,15 @@ import (
 	"github.com/blevesearch/bleve/analysis/tokenizer/single"
 	"github.com/blevesearch/bleve/analysis/tokenizer/whitespace"
 	"github.com/blevesearch/bleve/document"
+	"github.com/blevesearch/bleve/index"
 	"github.com/blevesearch/bleve/index/scorch"
 	"github.com/blevesearch/bleve/index/upsidedown"
 	"github.com/blevesearch/bleve/mapping"
 	"github.com/blevesearch/bleve/search"
+	"github.com/blevesearch/bleve/search/collector"
 	"github.com/blevesearch/bleve/search/highlight/highlighter/html"
 	"github.com/blevesearch/bleve/search/query"
+	"github.com/blevesearch/bleve/search/searcher"
 )
 
 func TestSearchResultString(t *testing.T) {
@@ -1264,3 +1267,362 @@ func TestDuplicateLocationsIssue1168(t *testing.T) {
 		t.Fatalf("duplicate marty")
 	}
 }
+
+func TestFacetsOnlySearch(t *testing.T) {
+	idx, err := NewMemOnly(NewIndexMapping())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err = idx.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	docs := map[string]map[string]interface{}{
+		"a": {"type": "fruit", "name": "apple"},
+		"b": {"type": "fruit", "name": "banana"},
+		"c": {"type": "vegetable", "name": "carrot"},
+	}
+	for id, doc := range docs {
+		err = idx.Index(id, doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	sr := NewSearchRequestOptions(NewMatchAllQuery(), 0, 0, false)
+	sr.AddFacet("types", NewFacetRequest("type", 10))
+	res, err := idx.Search(sr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Total != 3 {
+		t.Fatalf("expected 3 total hits, got %d", res.Total)
+	}
+	if len(res.Hits) != 0 {
+		t.Fatalf("expected no hits, got %d", len(res.Hits))
+	}
+	if res.MaxScore != 1.0 {
+		t.Errorf("expected max score 1.0, got %f", res.MaxScore)
+	}
+	types := res.Facets["types"]
+	if types == nil || types.Total != 3 || len(types.Terms) != 2 {
+		t.Fatalf("unexpected facet result: %#v", types)
+	}
+	if types.Terms[0].Term != "fruit" || types.Terms[0].Count != 2 {
+		t.Errorf("expected fruit(2) first, got %s(%d)",
+			types.Terms[0].Term, types.Terms[0].Count)
+	}
+}
+
+func TestSearchExplainPlan(t *testing.T) {
+	idx, err := NewMemOnly(NewIndexMapping())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err = idx.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	docs := map[string]map[string]interface{}{
+		"a": {"type": "fruit", "name": "apple"},
+		"b": {"type": "fruit", "name": "banana"},
+		"c": {"type": "vegetable", "name": "carrot"},
+	}
+	for id, doc := range docs {
+		err = idx.Index(id, doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var sr *SearchRequest
+	err = json.Unmarshal([]byte(`{
+		"query": {"conjuncts": [
+			{"term": "fruit", "field": "type"},
+			{"term": "banana", "field": "name"}
+		]},
+		"explainPlan": true
+	}`), &sr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := idx.Search(sr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Total != 1 {
+		t.Fatalf("expected 1 hit, got %d", res.Total)
+	}
+	if res.Plan == nil || res.Plan.Searcher != "conjunction" ||
+		res.Plan.Cost != 1 || len(res.Plan.Children) != 2 {
+		t.Fatalf("unexpected plan: %s", res.Plan)
+	}
+	if res.Plan.Children[0].Term != "banana" || res.Plan.Children[1].Term != "fruit" {
+		t.Errorf("expected the cheapest clause first, got plan: %s", res.Plan)
+	}
+
+	sr.ExplainPlan = false
+	res, err = idx.Search(sr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Plan != nil {
+		t.Errorf("expected no plan, got: %s", res.Plan)
+	}
+}
+
+func TestSearchFieldGlob(t *testing.T) {
+	idx, err := NewMemOnly(NewIndexMapping())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err = idx.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	docs := map[string]map[string]interface{}{
+		"a": {"user": map[string]interface{}{"name": "marty", "city": "paris", "age": 30}},
+		"b": {"user": map[string]interface{}{"nick": "marty", "age": 40}, "owner": "jane"},
+		"c": {"owner": "marty", "age": 35},
+	}
+	for id, doc := range docs {
+		err = idx.Index(id, doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	min, max := 25.0, 36.0
+	tests := []struct {
+		query    query.Query
+		expected []string
+	}{
+		{query: &query.MatchQuery{Match: "marty", FieldVal: "user.*"},
+			expected: []string{"a", "b"}},
+		{query: &query.TermQuery{Term: "paris", FieldVal: "user.*"},
+			expected: []string{"a"}},
+		{query: &query.NumericRangeQuery{Min: &min, Max: &max, FieldVal: "*age"},
+			expected: []string{"a", "c"}},
+		{query: &query.MatchQuery{Match: "marty", FieldVal: "nobody.*"},
+			expected: nil},
+	}
+	for _, test := range tests {
+		req := NewSearchRequest(test.query)
+		req.SortBy([]string{"_id"})
+		res, err := idx.Search(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var ids []string
+		for _, hit := range res.Hits {
+			ids = append(ids, hit.ID)
+		}
+		if !reflect.DeepEqual(ids, test.expected) {
+			t.Errorf("%#v: expected hits %v, got %v", test.query, test.expected, ids)
+		}
+	}
+
+	req := NewSearchRequest(NewDocIDQuery([]string{"a"}))
+	req.Fields = []string{"user.*"}
+	res, err := idx.Search(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(res.Hits) != 1 {
+		t.Fatalf("expected 1 hit, got %d", len(res.Hits))
+	}
+	expected := map[string]interface{}{
+		"user.name": "marty",
+		"user.city": "paris",
+		"user.age":  30.0,
+	}
+	if !reflect.DeepEqual(res.Hits[0].Fields, expected) {
+		t.Errorf("expected fields %v, got %v", expected, res.Hits[0].Fields)
+	}
+}
+
+func TestGeohashGridFacet(t *testing.T) {
+	m := mapping.NewIndexMapping()
+	m.DefaultMapping.AddFieldMappingsAt("loc", mapping.NewGeoPointFieldMapping())
+
+	docs := []map[string]interface{}{
+		{"loc": []float64{2.3522, 48.8566}, "tag": "city"},
+		{"loc": []float64{2.2945, 48.8584}, "tag": "tower"},
+		{"loc": []float64{-0.1276, 51.5072}, "tag": "city"},
+		{"tag": "nowhere"},
+	}
+
+	// the documents are split across two indexes, merged by an alias
+	var idxs []Index
+	for i := 0; i < 2; i++ {
+		idx, err := NewMemOnly(m)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer func() {
+			err := idx.Close()
+			if err != nil {
+				t.Fatal(err)
+			}
+		}()
+		idxs = append(idxs, idx)
+	}
+	for i, doc := range docs {
+		err := idxs[i%2].Index(fmt.Sprintf("%d", i), doc)
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var sr *SearchRequest
+	err := json.Unmarshal([]byte(`{
+		"query": {"match_all": {}},
+		"size": 0,
+		"facets": {
+			"grid": {
+				"field": "loc",
+				"size": 10,
+				"geohash_precision": 3,
+				"facets": {
+					"tags": {"field": "tag", "size": 1}
+				}
+			}
+		}
+	}`), &sr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = sr.Validate()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	res, err := NewIndexAlias(idxs...).Search(sr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	grid := res.Facets["grid"]
+	if grid == nil || grid.Total != 3 || grid.Missing != 1 {
+		t.Fatalf("unexpected grid facet: %+v", grid)
+	}
+	if len(grid.GeohashCells) != 2 {
+		t.Fatalf("expected 2 cells, got %d", len(grid.GeohashCells))
+	}
+	paris, london := grid.GeohashCells[0], grid.GeohashCells[1]
+	if paris.Geohash != "u09" || paris.Count != 2 ||
+		london.Geohash != "gcp" || london.Count != 1 {
+		t.Errorf("unexpected cells %+v, %+v", paris, london)
+	}
+	// the nested facets are merged, then trimmed to their size
+	tags := paris.Facets["tags"]
+	if tags == nil || tags.Total != 2 || len(tags.Terms) != 1 || tags.Other != 1 {
+		t.Errorf("unexpected nested facet: %+v", tags)
+	}
+
+	sr.Facets["grid"].GeohashPrecision = 13
+	if sr.Validate() == nil {
+		t.Errorf("expected error for a geohash precision of 13")
+	}
+	sr.Facets["grid"].GeohashPrecision = 0
+	if sr.Validate() == nil {
+		t.Errorf("expected error for nested facets without a geohash grid")
+	}
+}
+
+func init() {
+	search.RegisterSearcherWrapper("test_exclude_ids",
+		func(s search.Searcher, reader index.IndexReader,
+			config map[string]interface{}) (search.Searcher, error) {
+			excluded := map[string]bool{}
+			ids, _ := config["ids"].([]interface{})
+			for _, id := range ids {
+				excluded[fmt.Sprint(id)] = true
+			}
+			return searcher.NewFilteringSearcher(s, func(d *search.DocumentMatch) bool {
+				id, err := reader.ExternalID(d.IndexInternalID)
+				return err == nil && !excluded[id]
+			}), nil
+		})
+	search.RegisterCollector("test_capped",
+		func(size, from int, sort search.SortOrder,
+			config map[string]interface{}) (search.Collector, error) {
+			max, ok := config["max"].(float64)
+			if !ok {
+				return nil, fmt.Errorf("test_capped collector needs a max")
+			}
+			if size > int(max) {
+				size = int(max)
+			}
+			return collector.NewTopNCollector(size, from, sort), nil
+		})
+}
+
+func TestSearchExtensions(t *testing.T) {
+	idx, err := NewMemOnly(NewIndexMapping())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		err = idx.Close()
+		if err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	for _, id := range []string{"a", "b", "c", "d"} {
+		err = idx.Index(id, map[string]interface{}{"name": "marty"})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var req *SearchRequest
+	err = json.Unmarshal([]byte(`{
+		"query": {"match": "marty", "field": "name"},
+		"sort": ["_id"],
+		"searcherWrappers": [{"name": "test_exclude_ids", "config": {"ids": ["b"]}}],
+		"collector": {"name": "test_capped", "config": {"max": 2}}
+	}`), &req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = req.Validate()
+	if err != nil {
+		t.Fatal(err)
+	}
+	res, err := idx.Search(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var ids []string
+	for _, hit := range res.Hits {
+		ids = append(ids, hit.ID)
+	}
+	if !reflect.DeepEqual(ids, []string{"a", "c"}) {
+		t.Errorf("expected hits [a c], got %v", ids)
+	}
+	if res.Total != 3 {
+		t.Errorf("expected total 3, got %d", res.Total)
+	}
+
+	req.Collector.Config = nil
+	_, err = idx.Search(req)
+	if err == nil {
+		t.Errorf("expected error from collector without config")
+	}
+
+	req.Collector = &SearchExtension{Name: "unknown"}
+	err = req.Validate()
+	if err == nil {
+		t.Errorf("expected error validating unknown collector")
+	}
+}